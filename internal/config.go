@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/thomasgormley/dev-cli-go/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+func handleConfigGet(stdout, stderr io.Writer, cfg *config.Config) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if c.NArg() != 1 {
+			return cli.Exit("Usage: dev config get <key>", 1)
+		}
+
+		value, err := cfg.Get(c.Args().First())
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		fmt.Fprintln(stdout, value)
+		return nil
+	}
+}
+
+func handleConfigSet(stdout, stderr io.Writer) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if c.NArg() != 2 {
+			return cli.Exit("Usage: dev config set <key> <value>", 1)
+		}
+
+		path, err := configPath(c.Bool("local"))
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		if err := config.Set(path, c.Args().Get(0), c.Args().Get(1)); err != nil {
+			return cli.Exit(err, 1)
+		}
+		return nil
+	}
+}
+
+func handleConfigList(stdout, stderr io.Writer, cfg *config.Config) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		for _, key := range cfg.Keys() {
+			value, err := cfg.Get(key)
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+			fmt.Fprintf(stdout, "%s = %s\n", key, value)
+		}
+		return nil
+	}
+}
+
+func handleConfigPath(stdout, stderr io.Writer) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		path, err := configPath(c.Bool("local"))
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		fmt.Fprintln(stdout, path)
+		return nil
+	}
+}
+
+func configPath(local bool) (string, error) {
+	if !local {
+		return config.Path(), nil
+	}
+
+	path, err := config.LocalPath()
+	if err != nil {
+		return "", fmt.Errorf("not in a git repo, can't find the repo config")
+	}
+	return path, nil
+}
@@ -0,0 +1,301 @@
+// Package config loads dev's settings from ~/.config/dev/config.toml, with
+// overrides from a .dev.toml at the root of the current repository.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/thomasgormley/dev-cli-go/internal/git"
+	"github.com/thomasgormley/dev-cli-go/internal/prtitle"
+)
+
+const localFilename = ".dev.toml"
+
+type Config struct {
+	GitHub GitHub `toml:"github"`
+	PR     PR     `toml:"pr"`
+	Diary  Diary  `toml:"diary"`
+	Test   Test   `toml:"test"`
+}
+
+type GitHub struct {
+	// Client is either "gh" to shell out to the gh CLI, or "api" to talk to
+	// the GitHub API directly
+	Client string `toml:"client"`
+}
+
+type PR struct {
+	// Base is the default base branch, TEAM_BRANCH and --base take precedence
+	Base string `toml:"base"`
+
+	// TitleRules derive titles from branch names, in order, replacing the
+	// default rules when set, e.g.
+	//
+	//	[[pr.title_rules]]
+	//	name = "jira"
+	//	pattern = '^(?P<ticket>[A-Z]+-\d+)_(?P<description>.+)$'
+	//	template = "{ticket}: {Description|words}"
+	TitleRules []prtitle.Rule `toml:"title_rules"`
+}
+
+type Diary struct {
+	Repo   string `toml:"repo"`
+	Remote string `toml:"remote"`
+	Branch string `toml:"branch"`
+
+	// Repos are listed with their current branch in new entries
+	Repos []string `toml:"repos"`
+}
+
+type Test struct {
+	FailedTestsFile string `toml:"failed_tests_file"`
+}
+
+// Default returns the configuration used when there is no config file
+func Default() *Config {
+	return &Config{
+		GitHub: GitHub{Client: "gh"},
+		Diary: Diary{
+			Repo:   "~/dev/engineering-diary",
+			Remote: "origin",
+			Branch: "main",
+		},
+		Test: Test{FailedTestsFile: "~/.dev-cli-failed-tests.json"},
+	}
+}
+
+// ValidationError points at the key, and the file it came from, that has an
+// invalid value.
+type ValidationError struct {
+	File    string
+	Key     string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%s: %s", e.Key, e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.File, e.Key, e.Message)
+}
+
+// Path returns the location of the global config file, following the XDG
+// base directory spec.
+func Path() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		dir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(dir, "dev", "config.toml")
+}
+
+// LocalPath returns the location of the repository's config file, which
+// overrides the global one.
+func LocalPath() (string, error) {
+	root, err := git.Root()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, localFilename), nil
+}
+
+// Load reads the global config file followed by the repository's, falling
+// back to the defaults for anything neither sets.
+func Load() (*Config, error) {
+	cfg := Default()
+
+	paths := []string{Path()}
+	if local, err := LocalPath(); err == nil {
+		paths = append(paths, local)
+	}
+
+	for _, path := range paths {
+		if err := cfg.mergeFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// mergeFile decodes path over the current values, so only the keys it sets
+// are overridden, then validates the result so errors point at this file.
+func (c *Config) mergeFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return c.merge(path, data)
+}
+
+func (c *Config) merge(path string, data []byte) error {
+	md, err := toml.Decode(string(data), c)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return &ValidationError{File: path, Key: undecoded[0].String(), Message: "unknown key"}
+	}
+
+	if err := c.Validate(); err != nil {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			validationErr.File = path
+		}
+		return err
+	}
+	return nil
+}
+
+// Validate checks the values are usable.
+func (c *Config) Validate() error {
+	if c.GitHub.Client != "gh" && c.GitHub.Client != "api" {
+		return &ValidationError{Key: "github.client", Message: fmt.Sprintf("must be gh or api, got %q", c.GitHub.Client)}
+	}
+
+	required := map[string]string{
+		"diary.repo":             c.Diary.Repo,
+		"diary.remote":           c.Diary.Remote,
+		"diary.branch":           c.Diary.Branch,
+		"test.failed_tests_file": c.Test.FailedTestsFile,
+	}
+	for _, key := range sortedKeys(required) {
+		if strings.TrimSpace(required[key]) == "" {
+			return &ValidationError{Key: key, Message: "must not be empty"}
+		}
+	}
+
+	if _, err := prtitle.Compile(c.PR.TitleRules); err != nil {
+		return &ValidationError{Key: "pr.title_rules", Message: err.Error()}
+	}
+
+	return nil
+}
+
+// TitleRules returns the configured title rules, or the defaults
+func (c *Config) TitleRules() []prtitle.Rule {
+	if len(c.PR.TitleRules) > 0 {
+		return c.PR.TitleRules
+	}
+	return prtitle.DefaultRules
+}
+
+// ExpandPath expands a leading ~ to the home directory.
+func ExpandPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return filepath.Join(os.Getenv("HOME"), path[1:])
+	}
+	return path
+}
+
+// Keys returns every key that can be read with Get and written with Set.
+func (c *Config) Keys() []string {
+	var keys []string
+	walkKeys(reflect.ValueOf(c).Elem(), func(key string, _ reflect.Value) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+// Get returns the value of key, e.g. diary.repo, as a string.
+func (c *Config) Get(key string) (string, error) {
+	field, ok := lookupKey(c, key)
+	if !ok {
+		return "", &ValidationError{Key: key, Message: "unknown key"}
+	}
+
+	if strs, ok := field.Interface().([]string); ok {
+		return strings.Join(strs, ", "), nil
+	}
+	if field.Kind() == reflect.Slice {
+		return fmt.Sprintf("%d entries", field.Len()), nil
+	}
+	return fmt.Sprint(field.Interface()), nil
+}
+
+// Set writes key to the config file at path, leaving its other keys alone.
+// The file is validated before it's written, so a bad value never lands.
+func Set(path, key, value string) error {
+	field, ok := lookupKey(Default(), key)
+	if !ok {
+		return &ValidationError{File: path, Key: key, Message: "unknown key"}
+	}
+
+	var typed any
+	switch field.Kind() {
+	case reflect.String:
+		typed = value
+	default:
+		return &ValidationError{File: path, Key: key, Message: "can't be set from the command line, edit the file instead"}
+	}
+
+	raw := make(map[string]any)
+	if _, err := toml.DecodeFile(path, &raw); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	section, name, _ := strings.Cut(key, ".")
+	table, ok := raw[section].(map[string]any)
+	if !ok {
+		table = make(map[string]any)
+	}
+	table[name] = typed
+	raw[section] = table
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(raw); err != nil {
+		return err
+	}
+
+	if err := Default().merge(path, buf.Bytes()); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func lookupKey(c *Config, key string) (reflect.Value, bool) {
+	var found reflect.Value
+	walkKeys(reflect.ValueOf(c).Elem(), func(k string, v reflect.Value) {
+		if k == key {
+			found = v
+		}
+	})
+	return found, found.IsValid()
+}
+
+// walkKeys calls fn with the dotted toml key of every field in each section.
+func walkKeys(v reflect.Value, fn func(key string, field reflect.Value)) {
+	for i := 0; i < v.NumField(); i++ {
+		section := v.Type().Field(i).Tag.Get("toml")
+		sectionValue := v.Field(i)
+		for j := 0; j < sectionValue.NumField(); j++ {
+			name := sectionValue.Type().Field(j).Tag.Get("toml")
+			fn(section+"."+name, sectionValue.Field(j))
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeOverridesOnlySetKeys(t *testing.T) {
+	cfg := Default()
+
+	global := `
+[diary]
+repo = "~/notes"
+
+[pr]
+base = "develop"
+`
+	local := `
+[pr]
+base = "team-branch"
+`
+	if err := cfg.merge("config.toml", []byte(global)); err != nil {
+		t.Fatalf("merge(global) returned error: %v", err)
+	}
+	if err := cfg.merge(".dev.toml", []byte(local)); err != nil {
+		t.Fatalf("merge(local) returned error: %v", err)
+	}
+
+	expected := Default()
+	expected.Diary.Repo = "~/notes"
+	expected.PR.Base = "team-branch"
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("expected %+v, got %+v", expected, cfg)
+	}
+}
+
+func TestMergeValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "unknown key",
+			input:    "[diary]\nrepoo = \"~/notes\"",
+			expected: ".dev.toml: diary.repoo: unknown key",
+		},
+		{
+			name:     "invalid client",
+			input:    "[github]\nclient = \"hub\"",
+			expected: `.dev.toml: github.client: must be gh or api, got "hub"`,
+		},
+		{
+			name:     "empty remote",
+			input:    "[diary]\nremote = \"\"",
+			expected: ".dev.toml: diary.remote: must not be empty",
+		},
+		{
+			name:     "invalid title rule",
+			input:    "[[pr.title_rules]]\nname = \"bad\"\npattern = \"(\"",
+			expected: ".dev.toml: pr.title_rules: rule bad: invalid pattern",
+		},
+		{
+			name:     "wrong type",
+			input:    "[diary]\nrepo = 1",
+			expected: ".dev.toml: toml:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Default().merge(".dev.toml", []byte(tt.input))
+			if err == nil || !strings.HasPrefix(err.Error(), tt.expected) {
+				t.Errorf("expected error starting with %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestGetAndKeys(t *testing.T) {
+	cfg := Default()
+
+	value, err := cfg.Get("diary.branch")
+	if err != nil || value != "main" {
+		t.Errorf("expected main, got %q (%v)", value, err)
+	}
+
+	var validationErr *ValidationError
+	if _, err := cfg.Get("diary.nope"); !errors.As(err, &validationErr) {
+		t.Errorf("expected a ValidationError for an unknown key, got %v", err)
+	}
+
+	keys := cfg.Keys()
+	for _, key := range []string{"github.client", "pr.base", "pr.title_rules", "diary.repo", "test.failed_tests_file"} {
+		found := false
+		for _, k := range keys {
+			found = found || k == key
+		}
+		if !found {
+			t.Errorf("expected %s in keys %v", key, keys)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev", "config.toml")
+
+	existing := "[[pr.title_rules]]\nname = \"jira\"\npattern = '^(?P<ticket>[A-Z]+-\\d+)$'\ntemplate = \"{ticket}\"\n"
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Set(path, "diary.repo", "~/notes"); err != nil {
+		t.Fatalf("Set() returned error: %v", err)
+	}
+	if err := Set(path, "github.client", "hub"); err == nil {
+		t.Errorf("expected an invalid value to be rejected")
+	}
+	if err := Set(path, "pr.title_rules", "x"); err == nil {
+		t.Errorf("expected title rules to be rejected")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Default()
+	if err := cfg.merge(path, data); err != nil {
+		t.Fatalf("failed to load written config: %v", err)
+	}
+	if cfg.Diary.Repo != "~/notes" || cfg.GitHub.Client != "gh" {
+		t.Errorf("unexpected config after Set: %+v", cfg)
+	}
+	if len(cfg.PR.TitleRules) != 1 || cfg.PR.TitleRules[0].Name != "jira" {
+		t.Errorf("expected existing title rules to be kept, got %+v", cfg.PR.TitleRules)
+	}
+}
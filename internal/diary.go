@@ -3,50 +3,111 @@ package cli
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
-	"path"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/thomasgormley/dev-cli-go/internal/diary"
 	"github.com/thomasgormley/dev-cli-go/internal/editor"
+	"github.com/thomasgormley/dev-cli-go/internal/git"
 	"github.com/urfave/cli/v2"
 )
 
-var diaryDir = path.Join(os.Getenv("HOME"), "dev", "engineering-diary")
+func handleDiaryInit(stdout, stderr io.Writer, d *diary.Diary) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		target := *d
+		if dir := c.Args().First(); dir != "" {
+			abs, err := filepath.Abs(dir)
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+			target.Dir = abs
+		}
+
+		remoteURL := c.String("remote")
+		if err := target.Init(remoteURL); err != nil {
+			return cli.Exit(gitErrorMessage(err), 1)
+		}
+
+		fmt.Fprintf(stdout, "Created diary in %s ✅\n", target.Dir)
+		if target.Dir != d.Dir {
+			fmt.Fprintf(stdout, "Point dev at it with `dev config set diary.repo %s` or DIARY_REPO\n", target.Dir)
+		}
+		if remoteURL != "" {
+			fmt.Fprintf(stdout, "Push it with `git -C %s push -u %s %s`\n", target.Dir, target.Remote, target.Branch)
+		}
+		return nil
+	}
+}
 
-func handleDiaryNew(stdout, stderr io.Writer) cli.ActionFunc {
+// requireDiary stops diary commands early, with a hint, when the diary
+// hasn't been created yet.
+func requireDiary(d *diary.Diary) error {
+	if !d.Exists() {
+		return cli.Exit(fmt.Sprintf("Diary repo not found at %s, create it with `dev diary init` or point DIARY_REPO at an existing one", d.Dir), 1)
+	}
+	return nil
+}
+
+// diaryErrorMessage is gitErrorMessage for the diary's repository, pointing
+// at the diary.repo setting when it isn't a git repository
+func diaryErrorMessage(d *diary.Diary, err error) string {
+	if errors.Is(err, git.ErrNotARepo) {
+		return fmt.Sprintf("%v\n%s isn't a git repository, set diary.repo to your diary or create one with `dev diary init`", err, d.Dir)
+	}
+	return gitErrorMessage(err)
+}
+
+func handleDiaryNew(stdout, stderr io.Writer, d *diary.Diary) cli.ActionFunc {
 	return func(c *cli.Context) error {
-		if err := diary.NewEntry(); err != nil {
+		if err := requireDiary(d); err != nil {
+			return err
+		}
+
+		date := time.Now()
+		if s := c.String("date"); s != "" {
+			t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Invalid --date %q, expected YYYY-MM-DD", s), 1)
+			}
+			date = t
+		}
+
+		err := d.NewEntry(date)
+		if errors.Is(err, diary.ErrEntryExists) {
+			fmt.Fprintf(stdout, "Entry for %s already exists: %s\n", date.Format(time.DateOnly), d.EntryPathFor(date))
+			return nil
+		}
+		if err != nil {
 			return cli.Exit(err, 1)
 		}
 
+		fmt.Fprintf(stdout, "Created %s ✅\n", d.EntryPathFor(date))
 		return nil
 	}
 }
 
-func handleDiaryOpen(stdout, stderr io.Writer) cli.ActionFunc {
+func handleDiaryOpen(stdout, stderr io.Writer, d *diary.Diary) cli.ActionFunc {
 	return func(c *cli.Context) error {
 		today := time.Now()
-		// stdout.Write([]byte("Opening today's diary entry, " + today.Format(time.DateOnly) + "...\n"))
-		// err := prepareCmd(nil, stdout, stderr, path.Join(diaryDir, "scripts", "open.sh")).Run()
-		// if err != nil {
-		// 	return cli.Exit(err, 1)
-		// }
 
 		editorPath, editorArgs, ok := editor.Lookup()
 		if !ok {
 			return cli.Exit("$EDITOR not set, can't open diary entry", 1)
 		}
 
-		diaryRepo, ok := diary.RepoPath()
-		if !ok {
-			return cli.Exit("Diary repo path not found", 1)
+		if err := requireDiary(d); err != nil {
+			return err
 		}
+		diaryRepo := d.Dir
 
-		entryPath, err := diary.EnsureEntryExists(today)
+		entryPath, err := d.EnsureEntryExists(today)
 		if err != nil {
 			return cli.Exit(err, 1)
 		}
@@ -75,25 +136,93 @@ func handleDiaryOpen(stdout, stderr io.Writer) cli.ActionFunc {
 		// then the file...
 		cmd = prepareCmd(c.Context, os.Stdin, stdout, stderr, editorPath, append(editorArgs, entryPath)...)
 
-		if err := cmd.Start(); err != nil {
-			return cli.Exit(err, 1)
+		if !c.Bool("sync-on-close") {
+			if err := cmd.Start(); err != nil {
+				return cli.Exit(err, 1)
+			}
+			return nil
 		}
 
-		return nil
+		// GUI editors return straight away unless told to wait, e.g.
+		// EDITOR="code --wait"
+		if err := cmd.Run(); err != nil {
+			return cli.Exit(fmt.Sprintf("Editor exited with %v, not syncing", err), 1)
+		}
+		return syncDiary(stdout, stderr, d)
 	}
 }
 
-func handleDiarySync(stdout, stderr io.Writer) cli.ActionFunc {
+func handleDiarySync(stdout, stderr io.Writer, d *diary.Diary) cli.ActionFunc {
 	return func(c *cli.Context) error {
-		stdout.Write([]byte("Syncing Diary repository with the remote...\n"))
-		if err := diary.SyncToRemote(); err != nil {
-			stderr.Write([]byte("Failed to sync ❌\n"))
-			return cli.Exit(err, 1)
+		if err := requireDiary(d); err != nil {
+			return err
+		}
+
+		if c.Bool("dry-run") {
+			result, err := d.SyncToRemote(diary.SyncOptions{DryRun: true})
+			if err != nil {
+				return cli.Exit(diaryErrorMessage(d, err), 1)
+			}
+			if result.CommitMessage == "" {
+				stdout.Write([]byte("Nothing to commit\n"))
+				return nil
+			}
+			fmt.Fprintf(stdout, "Would commit:\n\n%s\n", result.CommitMessage)
+			return nil
+		}
+
+		if c.Bool("watch") {
+			return watchDiary(c.Context, stdout, stderr, d, c.Duration("debounce"))
 		}
 
-		stdout.Write([]byte("Synced ✅\n"))
+		return syncDiary(stdout, stderr, d)
+	}
+}
+
+// syncDiary syncs the diary, explaining how to recover from conflicts.
+func syncDiary(stdout, stderr io.Writer, d *diary.Diary) error {
+	fmt.Fprintf(stdout, "Syncing Diary repository with %s/%s...\n", d.Remote, d.Branch)
+	result, err := d.SyncToRemote(diary.SyncOptions{})
+	var conflictErr *diary.ConflictError
+	if errors.As(err, &conflictErr) {
+		stderr.Write([]byte("Failed to sync ❌\n"))
+		fmt.Fprintf(stderr, "These entries conflict with %s/%s:\n", conflictErr.Remote, conflictErr.Branch)
+		for _, file := range conflictErr.Files {
+			fmt.Fprintf(stderr, "  %s\n", file)
+		}
+		return cli.Exit(fmt.Sprintf("Your commit is kept, resolve with `git -C %s pull --rebase %s %s` then sync again", d.Dir, conflictErr.Remote, conflictErr.Branch), 1)
+	}
+	if err != nil {
+		stderr.Write([]byte("Failed to sync ❌\n"))
+		return cli.Exit(diaryErrorMessage(d, err), 1)
+	}
+
+	if !result.Pushed {
+		stdout.Write([]byte("Already up to date ✅\n"))
 		return nil
 	}
+	stdout.Write([]byte("Synced ✅\n"))
+	return nil
+}
+
+// watchDiary syncs the diary now and whenever entries change, until
+// interrupted. Failed syncs are reported and retried on the next change.
+func watchDiary(ctx context.Context, stdout, stderr io.Writer, d *diary.Diary, debounce time.Duration) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	sync := func() {
+		if err := syncDiary(stdout, stderr, d); err != nil {
+			fmt.Fprintln(stderr, err)
+		}
+	}
+
+	sync()
+	fmt.Fprintf(stdout, "Watching %s for changes, press Ctrl+C to stop\n", filepath.Join(d.Dir, "docs"))
+	if err := d.Watch(ctx, debounce, sync); err != nil {
+		return cli.Exit(fmt.Sprintf("Stopped watching: %v", err), 1)
+	}
+	return nil
 }
 
 func prepareCmd(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, name string, args ...string) *exec.Cmd {
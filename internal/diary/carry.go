@@ -0,0 +1,51 @@
+package diary
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// uncheckedTask matches an open Markdown task, e.g. "  - [ ] write tests"
+var uncheckedTask = regexp.MustCompile(`^(\s*[-*+]) \[ \] (.*\S.*)$`)
+
+// carriedOverHeading is appended to new entries whose template doesn't use
+// .CarriedOver itself
+const carriedOverHeading = "## Carried over"
+
+// uncheckedTasks returns the open tasks in content, keeping their indentation
+// so nested tasks stay nested.
+func uncheckedTasks(content string) []string {
+	var tasks []string
+	for _, line := range strings.Split(content, "\n") {
+		if uncheckedTask.MatchString(line) {
+			tasks = append(tasks, strings.TrimRight(line, " \t\r"))
+		}
+	}
+	return tasks
+}
+
+// markCarriedOver rewrites the open tasks in the entry at path as "- [>]",
+// linking to where they were carried over to.
+func markCarriedOver(path, link string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		match := uncheckedTask.FindStringSubmatch(strings.TrimRight(line, " \t\r"))
+		if match == nil {
+			continue
+		}
+		lines[i] = fmt.Sprintf("%s [>] %s ([carried over](%s))", match[1], match[2], link)
+	}
+
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+func appendCarriedOver(entry string, tasks []string) string {
+	return strings.TrimRight(entry, "\n") + "\n\n" + carriedOverHeading + "\n\n" + strings.Join(tasks, "\n") + "\n"
+}
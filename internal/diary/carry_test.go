@@ -0,0 +1,99 @@
+package diary
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUncheckedTasks(t *testing.T) {
+	content := `# 2024-03-04
+
+- [x] done
+- [ ] review PR
+  - [ ] nested follow up
+* [ ] starred
+- [ ]
+- [>] already carried
+Not a task - [ ] inline
+`
+
+	expected := []string{
+		"- [ ] review PR",
+		"  - [ ] nested follow up",
+		"* [ ] starred",
+	}
+	if got := uncheckedTasks(content); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestNewEntryCarriesOverTasks(t *testing.T) {
+	d := New(t.TempDir(), "origin", "main")
+	monday, tuesday := date(t, "2024-03-04"), date(t, "2024-03-05")
+
+	if err := os.MkdirAll(filepath.Dir(d.EntryPathFor(monday)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	previous := "# 2024-03-04\n\n- [x] done\n- [ ] review PR\n"
+	if err := os.WriteFile(d.EntryPathFor(monday), []byte(previous), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.NewEntry(tuesday); err != nil {
+		t.Fatal(err)
+	}
+
+	content := readEntry(t, d, tuesday)
+	if !strings.Contains(content, "## Carried over\n\n- [ ] review PR\n") {
+		t.Errorf("expected the task to be carried over, got:\n%s", content)
+	}
+
+	expected := "# 2024-03-04\n\n- [x] done\n- [>] review PR ([carried over](2024-03-05.md))\n"
+	if content := readEntry(t, d, monday); content != expected {
+		t.Errorf("expected previous entry %q, got %q", expected, content)
+	}
+
+	// It's still open in Tuesday's entry so carries on to Wednesday, leaving
+	// Monday's entry alone
+	wednesday := date(t, "2024-03-06")
+	if err := d.NewEntry(wednesday); err != nil {
+		t.Fatal(err)
+	}
+	if content := readEntry(t, d, wednesday); !strings.Contains(content, "- [ ] review PR") {
+		t.Errorf("expected the task to carry over again, got:\n%s", content)
+	}
+	if content := readEntry(t, d, monday); content != expected {
+		t.Errorf("expected previous entry to be unchanged, got %q", content)
+	}
+}
+
+func TestNewEntryCarriesOverWithCustomTemplate(t *testing.T) {
+	d := New(t.TempDir(), "origin", "main")
+	if err := os.MkdirAll(filepath.Join(d.Dir, "templates"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(d.Dir, entryTemplatePath), []byte("# {{ .Date }}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	monday := date(t, "2024-03-04")
+	if err := os.MkdirAll(filepath.Dir(d.EntryPathFor(monday)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(d.EntryPathFor(monday), []byte("- [ ] review PR\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tuesday := date(t, "2024-03-05")
+	if err := d.NewEntry(tuesday); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# 2024-03-05\n\n## Carried over\n\n- [ ] review PR\n"
+	if content := readEntry(t, d, tuesday); content != expected {
+		t.Errorf("expected %q, got %q", expected, content)
+	}
+}
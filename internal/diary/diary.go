@@ -1,10 +1,8 @@
 package diary
 
 import (
-	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path"
 	"strings"
 	"time"
@@ -19,39 +17,60 @@ func DateStringsFor(t time.Time) (year, month, full string) {
 	return
 }
 
-func RepoPath() (string, bool) {
-	// return os.LookupEnv("DIARY_REPO")
-	var diaryDir = path.Join(os.Getenv("HOME"), "dev", "engineering-diary")
-	return diaryDir, true
+// Diary is an engineering diary repository, with entries stored as
+// docs/YYYY/MM/YYYY-MM-DD.md
+type Diary struct {
+	Dir string
+
+	// Remote and Branch are where SyncToRemote pushes to
+	Remote string
+	Branch string
+
+	// Repos are the repositories whose current branches are listed in new
+	// entries
+	Repos []string
 }
 
-func EntryPathFor(t time.Time) (string, error) {
-	year, month, full := DateStringsFor(t)
+// RepoPath returns where the diary lives, DIARY_REPO taking precedence over
+// the configured path.
+func RepoPath(configured string) string {
+	if dir := os.Getenv("DIARY_REPO"); dir != "" {
+		return dir
+	}
+	return configured
+}
 
-	repo, ok := RepoPath()
-	if !ok {
-		return "", fmt.Errorf("DIARY_REPO environment variable not set")
+func New(dir, remote, branch string) *Diary {
+	return &Diary{
+		Dir:    dir,
+		Remote: remote,
+		Branch: branch,
 	}
+}
 
-	return path.Join(repo, "docs", year, month, fmt.Sprintf("%s.md", full)), nil
+// repo returns the diary's git repository
+func (d *Diary) repo() *git.Repo {
+	return git.Open(d.Dir)
 }
 
-func EntryExists(t time.Time) bool {
-	path, err := EntryPathFor(t)
+// Exists reports whether the diary repository has been created
+func (d *Diary) Exists() bool {
+	info, err := os.Stat(d.Dir)
+	return err == nil && info.IsDir()
+}
 
-	if err != nil {
-		return false
-	}
+func (d *Diary) EntryPathFor(t time.Time) string {
+	year, month, full := DateStringsFor(t)
+	return path.Join(d.Dir, "docs", year, month, fmt.Sprintf("%s.md", full))
+}
 
-	_, err = os.Stat(path)
+func (d *Diary) EntryExists(t time.Time) bool {
+	_, err := os.Stat(d.EntryPathFor(t))
 	return err == nil
 }
 
-func EnsureEntryExists(t time.Time) (string, error) {
-	entryPath, err := EntryPathFor(t)
-	if err != nil {
-		return "", err
-	}
+func (d *Diary) EnsureEntryExists(t time.Time) (string, error) {
+	entryPath := d.EntryPathFor(t)
 
 	// Check if the entry file exists
 	_, statErr := os.Stat(entryPath)
@@ -65,82 +84,146 @@ func EnsureEntryExists(t time.Time) (string, error) {
 		return "", mkErr
 	}
 
-	if err := NewEntry(); err != nil {
+	if err := d.NewEntry(t); err != nil {
 		return "", err
 	}
 
 	return entryPath, nil
 }
 
-func NewEntry() error {
-	repo, ok := RepoPath()
-	if !ok {
-		return errors.New("unable to find diary repo")
-	}
-	return exec.Command(path.Join(repo, "scripts", "new-entry.sh")).Run()
+// SyncOptions changes how SyncToRemote behaves
+type SyncOptions struct {
+	// DryRun reports what would be committed without touching the repository
+	DryRun bool
+}
 
+// SyncResult describes what SyncToRemote did, or would do for a dry run
+type SyncResult struct {
+	// CommitMessage is empty when there was nothing to commit
+	CommitMessage string
+	Pushed        bool
 }
 
-func SyncToRemote() error {
-	repo, ok := RepoPath()
-	if !ok {
-		return errors.New("unable to find diary repo")
-	}
+// ConflictError lists the files that couldn't be rebased onto the remote.
+// The rebase is aborted so the diary is left as it was before the sync.
+type ConflictError struct {
+	Remote string
+	Branch string
+	Files  []string
+}
 
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("entries conflict with %s/%s: %s", e.Remote, e.Branch, strings.Join(e.Files, ", "))
+}
+
+// SyncToRemote commits changes to entries, rebases them onto d.Branch of
+// d.Remote so entries written elsewhere are picked up, then pushes.
+func (d *Diary) SyncToRemote(opts SyncOptions) (*SyncResult, error) {
 	docsDir := "docs"
 
 	// Check if docs directory exists
-	docsPath := path.Join(repo, docsDir)
+	docsPath := path.Join(d.Dir, docsDir)
 	if _, err := os.Stat(docsPath); os.IsNotExist(err) {
-		return fmt.Errorf("directory %s does not exist", docsDir)
+		return nil, fmt.Errorf("directory %s does not exist", docsDir)
 	}
 
-	// Change to repository directory and check if it's a git repo
-	if err := os.Chdir(repo); err != nil {
-		return fmt.Errorf("failed to change to repository directory: %w", err)
-	}
+	repo := d.repo()
 
 	// Check if it's a git repository
-	if !git.IsRepo() {
-		return errors.New("not a git repository")
+	if !repo.IsRepo() {
+		return nil, fmt.Errorf("%s: %w", d.Dir, git.ErrNotARepo)
+	}
+
+	result := &SyncResult{}
+
+	if opts.DryRun {
+		// Status includes untracked entries, which aren't staged for a dry run
+		status, err := repo.Status(docsDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for changes: %w", err)
+		}
+		if strings.TrimSpace(status) != "" {
+			if result.CommitMessage, err = createCommitMessage(repo, docsDir); err != nil {
+				return nil, fmt.Errorf("failed to create commit message: %w", err)
+			}
+		}
+		return result, nil
 	}
 
 	// Add changes to staging area
-	if err := git.Add(docsDir); err != nil {
-		return fmt.Errorf("failed to add changes to staging: %w", err)
+	if err := repo.Add(docsDir); err != nil {
+		return nil, fmt.Errorf("failed to add changes to staging: %w", err)
 	}
 
 	// Check for uncommitted changes
-	hasChanges, err := git.HasUncommittedChanges(docsDir)
+	hasChanges, err := repo.HasUncommittedChanges(docsDir)
 	if err != nil {
-		return fmt.Errorf("failed to check for changes: %w", err)
+		return nil, fmt.Errorf("failed to check for changes: %w", err)
 	}
 
-	if !hasChanges {
-		return nil // No changes to commit
+	if hasChanges {
+		// Get file status and create commit message
+		if result.CommitMessage, err = createCommitMessage(repo, docsDir); err != nil {
+			return nil, fmt.Errorf("failed to create commit message: %w", err)
+		}
+
+		// Commit the changes
+		if err := repo.Commit(result.CommitMessage); err != nil {
+			return nil, fmt.Errorf("failed to commit changes: %w", err)
+		}
 	}
 
-	// Get file status and create commit message
-	commitMessage, err := createCommitMessage(docsDir)
-	if err != nil {
-		return fmt.Errorf("failed to create commit message: %w", err)
+	if err := repo.Fetch(d.Remote); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", d.Remote, err)
 	}
 
-	// Commit the changes
-	if err := git.Commit(commitMessage); err != nil {
-		return fmt.Errorf("failed to commit changes: %w", err)
+	remoteBranch := d.Remote + "/" + d.Branch
+	if repo.RefExists(remoteBranch) {
+		if err := d.rebaseOntoRemote(repo); err != nil {
+			return nil, err
+		}
+
+		unpushed, err := repo.Log(remoteBranch + "..HEAD")
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare with %s: %w", remoteBranch, err)
+		}
+		if len(unpushed) == 0 {
+			return result, nil
+		}
 	}
 
 	// Push to remote
-	if err := git.Push("origin", "main"); err != nil {
-		return fmt.Errorf("failed to push to remote repository: %w", err)
+	if err := repo.Push(d.Remote, d.Branch); err != nil {
+		return nil, fmt.Errorf("failed to push to remote repository: %w", err)
+	}
+	result.Pushed = true
+
+	return result, nil
+}
+
+// rebaseOntoRemote pulls the remote branch with --rebase, aborting and
+// returning a ConflictError if any entries conflict.
+func (d *Diary) rebaseOntoRemote(repo *git.Repo) error {
+	pullErr := repo.PullRebase(d.Remote, d.Branch)
+	if pullErr == nil {
+		return nil
 	}
 
-	return nil
+	files, err := repo.ConflictedFiles()
+	if err != nil || len(files) == 0 {
+		// Nothing to abort when the pull failed before rebasing
+		repo.AbortRebase()
+		return fmt.Errorf("failed to rebase onto %s/%s: %w", d.Remote, d.Branch, pullErr)
+	}
+
+	if err := repo.AbortRebase(); err != nil {
+		return fmt.Errorf("failed to abort rebase after conflicts in %s: %w", strings.Join(files, ", "), err)
+	}
+	return &ConflictError{Remote: d.Remote, Branch: d.Branch, Files: files}
 }
 
-func createCommitMessage(dir string) (string, error) {
-	statusOutput, err := git.Status(dir)
+func createCommitMessage(repo *git.Repo, dir string) (string, error) {
+	statusOutput, err := repo.Status(dir)
 	if err != nil {
 		return "", err
 	}
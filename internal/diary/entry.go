@@ -0,0 +1,259 @@
+package diary
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/thomasgormley/dev-cli-go/internal/git"
+)
+
+var ErrEntryExists = errors.New("entry already exists")
+
+// entryTemplatePath is where a diary can override DefaultEntryTemplate
+const entryTemplatePath = "templates/entry.md"
+
+// DefaultEntryTemplate is used when the diary doesn't have its own template,
+// see EntryData for the available fields.
+const DefaultEntryTemplate = `# {{ .Date }}
+
+{{ .Weekday }}, week {{ .ISOWeek }}{{ if .Yesterday }} · [Previous entry]({{ .Yesterday }}){{ end }}
+
+## Notes
+
+## Todo
+
+- [ ] 
+{{- if .CarriedOver }}
+
+## Carried over
+{{ range .CarriedOver }}
+{{ . }}
+{{- end }}
+{{- end }}
+{{- if .Branches }}
+
+## Branches
+{{ range $repo, $branch := .Branches }}
+- {{ $repo }}: ` + "`{{ $branch }}`" + `
+{{- end }}
+{{- end }}
+`
+
+// EntryData is passed to the entry template
+type EntryData struct {
+	// Time is the day the entry is for
+	Time time.Time
+	// Date is formatted as 2006-01-02
+	Date    string
+	Weekday string
+	ISOWeek int
+	// Yesterday links to the most recent entry before this one, relative to
+	// this entry, or is empty when there isn't one
+	Yesterday string
+	// Branches maps each of the diary's repos to its current branch
+	Branches map[string]string
+	// CarriedOver are the unchecked tasks from the previous entry
+	CarriedOver []string
+}
+
+// Entry is an existing diary entry
+type Entry struct {
+	Date time.Time
+	Path string
+}
+
+// NewEntry creates the entry for t from the diary's entry template, carrying
+// over any unchecked tasks from the previous entry and marking them there as
+// carried over.
+func (d *Diary) NewEntry(t time.Time) error {
+	entryPath := d.EntryPathFor(t)
+	if _, err := os.Stat(entryPath); err == nil {
+		return fmt.Errorf("%s: %w", entryPath, ErrEntryExists)
+	}
+
+	tmpl, err := d.entryTemplate()
+	if err != nil {
+		return err
+	}
+
+	data, previous, err := d.entryData(t)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", entryTemplatePath, err)
+	}
+
+	content := buf.String()
+	if len(data.CarriedOver) > 0 && !usesField(tmpl, "CarriedOver") {
+		content = appendCarriedOver(content, data.CarriedOver)
+	}
+
+	if err := os.MkdirAll(path.Dir(entryPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(entryPath, []byte(content), 0644); err != nil {
+		return err
+	}
+
+	if len(data.CarriedOver) == 0 {
+		return nil
+	}
+	link, err := relativeLink(previous.Path, entryPath)
+	if err != nil {
+		return err
+	}
+	return markCarriedOver(previous.Path, link)
+}
+
+// usesField reports whether the template refers to .name, so custom
+// templates that predate a field still get its content.
+func usesField(tmpl *template.Template, name string) bool {
+	return strings.Contains(tmpl.Root.String(), "."+name)
+}
+
+// relativeLink returns a Markdown link target for to, relative to the entry
+// at from.
+func relativeLink(from, to string) (string, error) {
+	rel, err := filepath.Rel(path.Dir(from), to)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func (d *Diary) entryTemplate() (*template.Template, error) {
+	text := DefaultEntryTemplate
+	content, err := os.ReadFile(path.Join(d.Dir, entryTemplatePath))
+	switch {
+	case err == nil:
+		text = string(content)
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+
+	tmpl, err := template.New(entryTemplatePath).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid entry template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// entryData returns the template data for t along with the previous entry,
+// which is only set when there are tasks to carry over from it.
+func (d *Diary) entryData(t time.Time) (EntryData, Entry, error) {
+	_, week := t.ISOWeek()
+	data := EntryData{
+		Time:     t,
+		Date:     t.Format("2006-01-02"),
+		Weekday:  t.Weekday().String(),
+		ISOWeek:  week,
+		Branches: make(map[string]string),
+	}
+
+	previous, ok, err := d.PreviousEntry(t)
+	if err != nil {
+		return EntryData{}, Entry{}, err
+	}
+	if ok {
+		data.Yesterday, err = relativeLink(d.EntryPathFor(t), previous.Path)
+		if err != nil {
+			return EntryData{}, Entry{}, err
+		}
+
+		content, err := os.ReadFile(previous.Path)
+		if err != nil {
+			return EntryData{}, Entry{}, err
+		}
+		data.CarriedOver = uncheckedTasks(string(content))
+	}
+
+	names := repoNames(d.Repos)
+	for _, repo := range d.Repos {
+		branch, err := git.Open(repo).CurrentBranch()
+		if err != nil {
+			// A repo that's been moved or deleted shouldn't stop the entry
+			// being created
+			continue
+		}
+		data.Branches[names[repo]] = branch
+	}
+
+	return data, previous, nil
+}
+
+// repoNames names each repo by its directory, or by its path as configured
+// when another repo has the same directory name, e.g. ~/work/api and
+// ~/oss/api.
+func repoNames(repos []string) map[string]string {
+	count := make(map[string]int)
+	for _, repo := range repos {
+		count[path.Base(repo)]++
+	}
+
+	names := make(map[string]string)
+	for _, repo := range repos {
+		names[repo] = path.Base(repo)
+		if count[path.Base(repo)] > 1 {
+			names[repo] = repo
+		}
+	}
+	return names
+}
+
+// Entries returns every entry in the diary, oldest first.
+func (d *Diary) Entries() ([]Entry, error) {
+	var entries []Entry
+	docs := path.Join(d.Dir, "docs")
+	err := filepath.WalkDir(docs, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || filepath.Ext(p) != ".md" {
+			return nil
+		}
+
+		date, err := time.ParseInLocation("2006-01-02", strings.TrimSuffix(entry.Name(), ".md"), time.Local)
+		if err != nil {
+			// Not an entry, e.g. a README
+			return nil
+		}
+		entries = append(entries, Entry{Date: date, Path: p})
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+	return entries, nil
+}
+
+// PreviousEntry returns the most recent entry before the day of t.
+func (d *Diary) PreviousEntry(t time.Time) (Entry, bool, error) {
+	entries, err := d.Entries()
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	day := t.Format("2006-01-02")
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Date.Format("2006-01-02") < day {
+			return entries[i], true, nil
+		}
+	}
+	return Entry{}, false, nil
+}
@@ -0,0 +1,153 @@
+package diary
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func readEntry(t *testing.T, d *Diary, day time.Time) string {
+	t.Helper()
+	content, err := os.ReadFile(d.EntryPathFor(day))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestNewEntryDefaultTemplate(t *testing.T) {
+	d := New(t.TempDir(), "origin", "main")
+
+	if err := d.NewEntry(date(t, "2024-03-04")); err != nil {
+		t.Fatal(err)
+	}
+
+	content := readEntry(t, d, date(t, "2024-03-04"))
+	for _, expected := range []string{"# 2024-03-04", "Monday, week 10", "## Todo"} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected entry to contain %q, got:\n%s", expected, content)
+		}
+	}
+	if strings.Contains(content, "Previous entry") {
+		t.Errorf("expected no previous entry link in the first entry, got:\n%s", content)
+	}
+}
+
+func TestNewEntryCustomTemplate(t *testing.T) {
+	d := New(t.TempDir(), "origin", "main")
+	if err := os.MkdirAll(filepath.Join(d.Dir, "templates"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	template := "{{ .Date }} {{ .Weekday }} {{ .Yesterday }}\n"
+	if err := os.WriteFile(filepath.Join(d.Dir, entryTemplatePath), []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.NewEntry(date(t, "2024-02-28")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.NewEntry(date(t, "2024-03-01")); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "2024-03-01 Friday ../02/2024-02-28.md\n"
+	if content := readEntry(t, d, date(t, "2024-03-01")); content != expected {
+		t.Errorf("expected %q, got %q", expected, content)
+	}
+}
+
+func TestNewEntryInvalidTemplate(t *testing.T) {
+	d := New(t.TempDir(), "origin", "main")
+	if err := os.MkdirAll(filepath.Join(d.Dir, "templates"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(d.Dir, entryTemplatePath), []byte("{{ .Tomorrow }}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.NewEntry(date(t, "2024-03-04")); err == nil {
+		t.Fatal("expected an error for an unknown field")
+	}
+	if d.EntryExists(date(t, "2024-03-04")) {
+		t.Error("expected no entry to be written")
+	}
+}
+
+func TestNewEntryExists(t *testing.T) {
+	d := New(t.TempDir(), "origin", "main")
+	day := date(t, "2024-03-04")
+
+	if err := d.NewEntry(day); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(d.EntryPathFor(day), []byte("my notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.NewEntry(day); !errors.Is(err, ErrEntryExists) {
+		t.Fatalf("expected ErrEntryExists, got %v", err)
+	}
+	if content := readEntry(t, d, day); content != "my notes" {
+		t.Errorf("expected the existing entry to be left alone, got %q", content)
+	}
+}
+
+func TestEntries(t *testing.T) {
+	d := New(t.TempDir(), "origin", "main")
+	for _, day := range []string{"2024-03-04", "2023-12-31", "2024-01-15"} {
+		if err := d.NewEntry(date(t, day)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(d.Dir, "docs", "README.md"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := d.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Date.Format("2006-01-02"))
+	}
+	expected := "2023-12-31 2024-01-15 2024-03-04"
+	if strings.Join(got, " ") != expected {
+		t.Errorf("expected %s, got %v", expected, got)
+	}
+
+	previous, ok, err := d.PreviousEntry(date(t, "2024-03-04"))
+	if err != nil || !ok {
+		t.Fatalf("expected a previous entry, got %v, %v", ok, err)
+	}
+	if previous.Date.Format("2006-01-02") != "2024-01-15" {
+		t.Errorf("expected 2024-01-15, got %s", previous.Date.Format("2006-01-02"))
+	}
+}
+
+func TestRepoNames(t *testing.T) {
+	names := repoNames([]string{"/home/me/work/api", "/home/me/oss/api", "/home/me/work/web"})
+
+	expected := map[string]string{
+		"/home/me/work/api": "/home/me/work/api",
+		"/home/me/oss/api":  "/home/me/oss/api",
+		"/home/me/work/web": "web",
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
@@ -0,0 +1,90 @@
+package diary
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/thomasgormley/dev-cli-go/internal/git"
+)
+
+// Files written by Init, relative to the repository root
+var scaffold = map[string]struct {
+	content string
+	mode    os.FileMode
+}{
+	"README.md": {
+		content: "# Engineering diary\n\nOne entry per day in `docs/YYYY/MM/YYYY-MM-DD.md`, managed with `dev diary`.\n",
+		mode:    0644,
+	},
+	"docs/.gitkeep": {
+		mode: 0644,
+	},
+	entryTemplatePath: {
+		content: DefaultEntryTemplate,
+		mode:    0644,
+	},
+}
+
+// Init scaffolds a new diary repository in d.Dir with an initial commit,
+// adding remoteURL as d.Remote when given.
+func (d *Diary) Init(remoteURL string) error {
+	if _, err := os.Stat(path.Join(d.Dir, ".git")); err == nil {
+		return fmt.Errorf("%s is already a git repository", d.Dir)
+	}
+
+	if err := os.MkdirAll(d.Dir, 0755); err != nil {
+		return err
+	}
+
+	repo, err := git.Init(d.Dir, d.Branch)
+	if err != nil {
+		return fmt.Errorf("failed to initialise git repository: %w", err)
+	}
+
+	// Leave anything already in the directory alone, only the files
+	// written here are committed
+	var written []string
+	for _, name := range scaffoldNames() {
+		p := path.Join(d.Dir, name)
+		if _, err := os.Stat(p); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(path.Dir(p), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, []byte(scaffold[name].content), scaffold[name].mode); err != nil {
+			return err
+		}
+		written = append(written, name)
+	}
+	if len(written) == 0 {
+		return fmt.Errorf("%s already has the diary files, commit them to finish", d.Dir)
+	}
+
+	if remoteURL != "" {
+		if err := repo.AddRemote(d.Remote, remoteURL); err != nil {
+			return fmt.Errorf("failed to add remote %s: %w", d.Remote, err)
+		}
+	}
+
+	if err := repo.Add(written...); err != nil {
+		return fmt.Errorf("failed to add changes to staging: %w", err)
+	}
+
+	if err := repo.Commit("Initial diary"); err != nil {
+		return fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return nil
+}
+
+func scaffoldNames() []string {
+	var names []string
+	for name := range scaffold {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,71 @@
+package diary
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setGitIdentity(t *testing.T) {
+	t.Helper()
+	t.Setenv("GIT_AUTHOR_NAME", "dev")
+	t.Setenv("GIT_AUTHOR_EMAIL", "dev@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "dev")
+	t.Setenv("GIT_COMMITTER_EMAIL", "dev@example.com")
+}
+
+func TestInit(t *testing.T) {
+	setGitIdentity(t)
+	d := New(filepath.Join(t.TempDir(), "diary"), "origin", "main")
+
+	if err := d.Init("git@example.com:me/diary.git"); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+
+	committed := strings.Fields(gitRun(t, d.Dir, "ls-files"))
+	expected := []string{"README.md", "docs/.gitkeep", "templates/entry.md"}
+	if strings.Join(committed, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected %q to be committed, got %q", expected, committed)
+	}
+	if remote := strings.TrimSpace(gitRun(t, d.Dir, "remote", "get-url", "origin")); remote != "git@example.com:me/diary.git" {
+		t.Errorf("expected the origin remote to be added, got %q", remote)
+	}
+
+	if err := d.Init(""); err == nil {
+		t.Errorf("expected Init to refuse an existing repository")
+	}
+}
+
+func TestInitKeepsExistingContent(t *testing.T) {
+	setGitIdentity(t)
+	d := New(t.TempDir(), "origin", "main")
+
+	files := map[string]string{
+		"README.md": "# My notes\n",
+		"notes.txt": "private\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(d.Dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := d.Init(""); err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+
+	readme, err := os.ReadFile(filepath.Join(d.Dir, "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(readme) != files["README.md"] {
+		t.Errorf("expected the existing README to be kept, got %q", readme)
+	}
+
+	committed := strings.Fields(gitRun(t, d.Dir, "ls-files"))
+	expected := []string{"docs/.gitkeep", "templates/entry.md"}
+	if strings.Join(committed, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected only the written files to be committed, got %q", committed)
+	}
+}
@@ -0,0 +1,150 @@
+package diary
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Query filters entries and the lines within them. The zero value matches
+// every line of every entry.
+type Query struct {
+	// Pattern matches lines, nil matches every line
+	Pattern *regexp.Regexp
+	// Since and Until bound the entry dates, inclusively, when set
+	Since time.Time
+	Until time.Time
+	// Tags must all appear in an entry, e.g. "oncall" for #oncall
+	Tags []string
+	// Context is the number of lines to include either side of a match
+	Context int
+}
+
+// Match is a line matching a Query, numbered from 1
+type Match struct {
+	Entry  Entry
+	Line   int
+	Text   string
+	Before []string
+	After  []string
+}
+
+var tagPattern = regexp.MustCompile(`(?:^|\s)#([\w-]+)`)
+
+// PhrasePattern matches phrase anywhere in a line, ignoring case.
+func PhrasePattern(phrase string) *regexp.Regexp {
+	return regexp.MustCompile("(?i)" + regexp.QuoteMeta(phrase))
+}
+
+// SplitTags separates #tag words from the rest of a query, so
+// "#oncall paging" filters on the oncall tag and searches for "paging".
+func SplitTags(words []string) (rest []string, tags []string) {
+	for _, word := range words {
+		if tag, ok := strings.CutPrefix(word, "#"); ok && tag != "" {
+			tags = append(tags, strings.ToLower(tag))
+			continue
+		}
+		rest = append(rest, word)
+	}
+	return rest, tags
+}
+
+// Search returns the lines matching q across every entry, oldest first.
+func (d *Diary) Search(q Query) ([]Match, error) {
+	entries, err := d.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	context := max(0, q.Context)
+
+	var matches []Match
+	for _, entry := range entries {
+		if !q.Since.IsZero() && entry.Date.Before(day(q.Since)) {
+			continue
+		}
+		if !q.Until.IsZero() && entry.Date.After(day(q.Until)) {
+			continue
+		}
+
+		content, err := os.ReadFile(entry.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Path, err)
+		}
+
+		lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+		tags := entryTags(lines)
+		if !hasAll(tags, q.Tags) {
+			continue
+		}
+
+		for i, line := range lines {
+			if !q.matches(line) {
+				continue
+			}
+			matches = append(matches, Match{
+				Entry:  entry,
+				Line:   i + 1,
+				Text:   line,
+				Before: lines[max(0, i-context):i],
+				After:  lines[i+1 : min(len(lines), i+1+context)],
+			})
+		}
+	}
+	return matches, nil
+}
+
+// matches reports whether line matches the pattern, or, for a tag only
+// query, mentions one of the tags.
+func (q Query) matches(line string) bool {
+	if q.Pattern != nil {
+		return q.Pattern.MatchString(line)
+	}
+	if len(q.Tags) > 0 {
+		return hasAny(lineTags(line), q.Tags)
+	}
+	return true
+}
+
+func lineTags(line string) map[string]bool {
+	tags := make(map[string]bool)
+	for _, match := range tagPattern.FindAllStringSubmatch(line, -1) {
+		tags[strings.ToLower(match[1])] = true
+	}
+	return tags
+}
+
+func entryTags(lines []string) map[string]bool {
+	tags := make(map[string]bool)
+	for _, line := range lines {
+		for tag := range lineTags(line) {
+			tags[tag] = true
+		}
+	}
+	return tags
+}
+
+func hasAll(tags map[string]bool, want []string) bool {
+	for _, tag := range want {
+		if !tags[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAny(tags map[string]bool, want []string) bool {
+	for _, tag := range want {
+		if tags[tag] {
+			return true
+		}
+	}
+	return false
+}
+
+// day truncates t to midnight in its location, matching entry dates
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,142 @@
+package diary
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func writeEntries(t *testing.T, d *Diary, entries map[string]string) {
+	t.Helper()
+	for day, content := range entries {
+		p := d.EntryPathFor(date(t, day))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	d := New(t.TempDir(), "origin", "main")
+	writeEntries(t, d, map[string]string{
+		"2024-03-04": "# 2024-03-04\n\nPaged for the billing outage #oncall\nFixed the retry loop\n",
+		"2024-03-05": "# 2024-03-05\n\nReviewed billing PR\n",
+		"2024-04-01": "# 2024-04-01\n\n#oncall again, disk full\n",
+	})
+
+	type hit struct {
+		date string
+		line int
+	}
+
+	tests := []struct {
+		name     string
+		query    Query
+		expected []hit
+	}{
+		{
+			name:     "phrase ignores case",
+			query:    Query{Pattern: PhrasePattern("BILLING")},
+			expected: []hit{{"2024-03-04", 3}, {"2024-03-05", 3}},
+		},
+		{
+			name:     "regex",
+			query:    Query{Pattern: regexp.MustCompile(`^Fix|disk \w+$`)},
+			expected: []hit{{"2024-03-04", 4}, {"2024-04-01", 3}},
+		},
+		{
+			name:     "since and until are inclusive",
+			query:    Query{Pattern: PhrasePattern("#"), Since: date(t, "2024-03-05"), Until: date(t, "2024-03-05")},
+			expected: []hit{{"2024-03-05", 1}},
+		},
+		{
+			name:     "tag only matches tagged lines",
+			query:    Query{Tags: []string{"oncall"}},
+			expected: []hit{{"2024-03-04", 3}, {"2024-04-01", 3}},
+		},
+		{
+			name:     "tag filters entries",
+			query:    Query{Pattern: PhrasePattern("retry"), Tags: []string{"oncall"}},
+			expected: []hit{{"2024-03-04", 4}},
+		},
+		{
+			name:  "tag must match",
+			query: Query{Pattern: PhrasePattern("billing"), Tags: []string{"release"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := d.Search(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []hit
+			for _, match := range matches {
+				got = append(got, hit{match.Entry.Date.Format(time.DateOnly), match.Line})
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchContext(t *testing.T) {
+	d := New(t.TempDir(), "origin", "main")
+	writeEntries(t, d, map[string]string{
+		"2024-03-04": "one\ntwo\nthree\nfour\n",
+	})
+
+	matches, err := d.Search(Query{Pattern: PhrasePattern("two"), Context: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(matches))
+	}
+
+	match := matches[0]
+	if len(match.Before) != 1 || match.Before[0] != "one" {
+		t.Errorf("expected [one] before, got %q", match.Before)
+	}
+	if len(match.After) != 2 || match.After[1] != "four" {
+		t.Errorf("expected [three four] after, got %q", match.After)
+	}
+}
+
+func TestSearchNegativeContext(t *testing.T) {
+	d := New(t.TempDir(), "origin", "main")
+	writeEntries(t, d, map[string]string{
+		"2024-03-04": "one\ntwo\nthree\n",
+	})
+
+	matches, err := d.Search(Query{Pattern: PhrasePattern("two"), Context: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || len(matches[0].Before) != 0 || len(matches[0].After) != 0 {
+		t.Errorf("expected a match without context, got %+v", matches)
+	}
+}
+
+func TestSplitTags(t *testing.T) {
+	rest, tags := SplitTags([]string{"#OnCall", "disk", "#", "full"})
+	if len(rest) != 3 || rest[0] != "disk" || rest[1] != "#" || rest[2] != "full" {
+		t.Errorf("expected [disk # full], got %q", rest)
+	}
+	if len(tags) != 1 || tags[0] != "oncall" {
+		t.Errorf("expected [oncall], got %q", tags)
+	}
+}
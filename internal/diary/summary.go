@@ -0,0 +1,185 @@
+package diary
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	headingPattern = regexp.MustCompile(`^#{2,6}\s+(.+?)\s*$`)
+	completedTask  = regexp.MustCompile(`^\s*[-*+] \[[xX]\] (.*\S.*)$`)
+	carriedTask    = regexp.MustCompile(`^\s*[-*+] \[>\] `)
+	emptyTask      = regexp.MustCompile(`^\s*[-*+] \[ \]\s*$`)
+	pullRequestURL = regexp.MustCompile(`https://github\.com/[\w.-]+/[\w.-]+/pull/\d+`)
+)
+
+// Summary rolls up the entries between two days
+type Summary struct {
+	From, Until time.Time
+	Entries     []Entry
+
+	// Sections are the entries' headings in the order first seen, with each
+	// day's content under them
+	Sections []Section
+	// Completed are the checked off tasks, oldest first
+	Completed []Task
+	// Tags counts each tag's mentions
+	Tags map[string]int
+	// PullRequests are the linked pull request URLs, in the order first seen
+	PullRequests []string
+}
+
+// Section is a heading's content across days
+type Section struct {
+	Heading string
+	Days    []Day
+}
+
+// Day is the content of a section in one entry
+type Day struct {
+	Date  time.Time
+	Lines []string
+}
+
+// Task is a completed task and the day it was in
+type Task struct {
+	Date time.Time
+	Text string
+}
+
+// WeekOf returns the Monday to Sunday week containing t.
+func WeekOf(t time.Time) (from, until time.Time) {
+	from = day(t).AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+	return from, from.AddDate(0, 0, 6)
+}
+
+// MonthOf returns the first and last days of the month containing t.
+func MonthOf(t time.Time) (from, until time.Time) {
+	from = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return from, from.AddDate(0, 1, -1)
+}
+
+// Summarize rolls up the entries from one day until another, inclusively.
+// Content before an entry's first ## heading is its title, so is left out,
+// as are carried over tasks which show up again in later entries.
+func (d *Diary) Summarize(from, until time.Time) (*Summary, error) {
+	summary := &Summary{From: day(from), Until: day(until), Tags: make(map[string]int)}
+	sections := make(map[string]int)
+	seenPRs := make(map[string]bool)
+
+	for t := summary.From; !t.After(summary.Until); t = t.AddDate(0, 0, 1) {
+		if !d.EntryExists(t) {
+			continue
+		}
+		entryPath := d.EntryPathFor(t)
+		content, err := os.ReadFile(entryPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entryPath, err)
+		}
+		summary.Entries = append(summary.Entries, Entry{Date: t, Path: entryPath})
+
+		heading := ""
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimRight(line, " \t\r")
+
+			for tag := range lineTags(line) {
+				summary.Tags[tag]++
+			}
+			for _, url := range pullRequestURL.FindAllString(line, -1) {
+				if !seenPRs[url] {
+					seenPRs[url] = true
+					summary.PullRequests = append(summary.PullRequests, url)
+				}
+			}
+
+			if match := headingPattern.FindStringSubmatch(line); match != nil {
+				heading = match[1]
+				continue
+			}
+			if match := completedTask.FindStringSubmatch(line); match != nil {
+				summary.Completed = append(summary.Completed, Task{Date: t, Text: match[1]})
+				continue
+			}
+			if heading == "" || strings.EqualFold(heading, "Carried over") ||
+				strings.TrimSpace(line) == "" || emptyTask.MatchString(line) || carriedTask.MatchString(line) {
+				continue
+			}
+
+			i, ok := sections[heading]
+			if !ok {
+				i = len(summary.Sections)
+				sections[heading] = i
+				summary.Sections = append(summary.Sections, Section{Heading: heading})
+			}
+			section := &summary.Sections[i]
+			if n := len(section.Days); n == 0 || !section.Days[n-1].Date.Equal(t) {
+				section.Days = append(section.Days, Day{Date: t})
+			}
+			last := &section.Days[len(section.Days)-1]
+			last.Lines = append(last.Lines, line)
+		}
+	}
+
+	return summary, nil
+}
+
+// Markdown renders the summary as a single report
+func (s *Summary) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Diary summary: %s to %s\n\n", s.From.Format(time.DateOnly), s.Until.Format(time.DateOnly))
+
+	switch len(s.Entries) {
+	case 0:
+		b.WriteString("No entries.\n")
+		return b.String()
+	case 1:
+		b.WriteString("1 entry.\n")
+	default:
+		fmt.Fprintf(&b, "%d entries.\n", len(s.Entries))
+	}
+
+	if len(s.Completed) > 0 {
+		b.WriteString("\n## Completed\n\n")
+		for _, task := range s.Completed {
+			fmt.Fprintf(&b, "- [x] %s (%s)\n", task.Text, task.Date.Format(time.DateOnly))
+		}
+	}
+
+	for _, section := range s.Sections {
+		fmt.Fprintf(&b, "\n## %s\n", section.Heading)
+		for _, day := range section.Days {
+			fmt.Fprintf(&b, "\n### %s\n\n%s\n", day.Date.Format("Monday 2006-01-02"), strings.Join(day.Lines, "\n"))
+		}
+	}
+
+	if len(s.PullRequests) > 0 {
+		b.WriteString("\n## Pull requests\n\n")
+		for _, url := range s.PullRequests {
+			fmt.Fprintf(&b, "- %s\n", url)
+		}
+	}
+
+	if len(s.Tags) > 0 {
+		tags := make([]string, 0, len(s.Tags))
+		for tag := range s.Tags {
+			tags = append(tags, tag)
+		}
+		sort.Slice(tags, func(i, j int) bool {
+			if s.Tags[tags[i]] != s.Tags[tags[j]] {
+				return s.Tags[tags[i]] > s.Tags[tags[j]]
+			}
+			return tags[i] < tags[j]
+		})
+
+		b.WriteString("\n## Tags\n\n")
+		for _, tag := range tags {
+			fmt.Fprintf(&b, "- #%s (%d)\n", tag, s.Tags[tag])
+		}
+	}
+
+	return b.String()
+}
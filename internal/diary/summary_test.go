@@ -0,0 +1,114 @@
+package diary
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeekAndMonthOf(t *testing.T) {
+	from, until := WeekOf(date(t, "2024-03-06"))
+	if from.Format(time.DateOnly) != "2024-03-04" || until.Format(time.DateOnly) != "2024-03-10" {
+		t.Errorf("expected 2024-03-04 to 2024-03-10, got %s to %s", from.Format(time.DateOnly), until.Format(time.DateOnly))
+	}
+
+	from, until = WeekOf(date(t, "2024-03-10"))
+	if from.Format(time.DateOnly) != "2024-03-04" {
+		t.Errorf("expected Sunday to be in the week starting 2024-03-04, got %s", from.Format(time.DateOnly))
+	}
+
+	from, until = MonthOf(date(t, "2024-02-14"))
+	if from.Format(time.DateOnly) != "2024-02-01" || until.Format(time.DateOnly) != "2024-02-29" {
+		t.Errorf("expected 2024-02-01 to 2024-02-29, got %s to %s", from.Format(time.DateOnly), until.Format(time.DateOnly))
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	d := New(t.TempDir(), "origin", "main")
+	writeEntries(t, d, map[string]string{
+		"2024-03-01": "# 2024-03-01\n\n## Notes\n\nLast week, not included\n",
+		"2024-03-04": `# 2024-03-04
+
+## Notes
+
+Paged for the billing outage #oncall
+
+## Todo
+
+- [x] fix retry loop https://github.com/acme/billing/pull/12
+- [>] review PR ([carried over](2024-03-05.md))
+- [ ]
+`,
+		"2024-03-05": `# 2024-03-05
+
+## Todo
+
+- [ ] review PR
+- [x] write postmortem #oncall
+
+## Carried over
+
+- [ ] review PR
+
+## Notes
+
+Shipped https://github.com/acme/billing/pull/12 and https://github.com/acme/billing/pull/13
+`,
+	})
+
+	summary, err := d.Summarize(date(t, "2024-03-04"), date(t, "2024-03-10"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# Diary summary: 2024-03-04 to 2024-03-10
+
+2 entries.
+
+## Completed
+
+- [x] fix retry loop https://github.com/acme/billing/pull/12 (2024-03-04)
+- [x] write postmortem #oncall (2024-03-05)
+
+## Notes
+
+### Monday 2024-03-04
+
+Paged for the billing outage #oncall
+
+### Tuesday 2024-03-05
+
+Shipped https://github.com/acme/billing/pull/12 and https://github.com/acme/billing/pull/13
+
+## Todo
+
+### Tuesday 2024-03-05
+
+- [ ] review PR
+
+## Pull requests
+
+- https://github.com/acme/billing/pull/12
+- https://github.com/acme/billing/pull/13
+
+## Tags
+
+- #oncall (2)
+`
+	if got := summary.Markdown(); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestSummarizeWithoutEntries(t *testing.T) {
+	d := New(t.TempDir(), "origin", "main")
+
+	summary, err := d.Summarize(date(t, "2024-03-04"), date(t, "2024-03-10"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# Diary summary: 2024-03-04 to 2024-03-10\n\nNo entries.\n"
+	if got := summary.Markdown(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
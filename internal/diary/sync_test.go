@@ -0,0 +1,182 @@
+package diary
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// cloneDiaries returns two diaries cloned from the same bare remote, as if on
+// two machines.
+func cloneDiaries(t *testing.T) (*Diary, *Diary) {
+	t.Helper()
+
+	setGitIdentity(t)
+
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	gitRun(t, root, "init", "-q", "--bare", "-b", "main", remote)
+
+	seed := filepath.Join(root, "seed")
+	gitRun(t, root, "clone", "-q", remote, seed)
+	writeEntries(t, New(seed, "origin", "main"), map[string]string{"2024-03-01": "# 2024-03-01\n"})
+	gitRun(t, seed, "add", ".")
+	gitRun(t, seed, "commit", "-q", "-m", "initial")
+	gitRun(t, seed, "push", "-q", "origin", "HEAD:main")
+
+	var diaries []*Diary
+	for _, name := range []string{"laptop", "desktop"} {
+		dir := filepath.Join(root, name)
+		gitRun(t, root, "clone", "-q", remote, dir)
+		diaries = append(diaries, New(dir, "origin", "main"))
+	}
+	return diaries[0], diaries[1]
+}
+
+func gitRun(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
+
+func TestSyncToRemotePicksUpRemoteEntries(t *testing.T) {
+	laptop, desktop := cloneDiaries(t)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeEntries(t, laptop, map[string]string{"2024-03-04": "from the laptop\n"})
+	if _, err := laptop.SyncToRemote(SyncOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := os.Getwd(); after != wd {
+		t.Errorf("expected the working directory to stay %s, got %s", wd, after)
+	}
+
+	writeEntries(t, desktop, map[string]string{"2024-03-05": "from the desktop\n"})
+	result, err := desktop.SyncToRemote(SyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Pushed || result.CommitMessage == "" {
+		t.Errorf("expected a commit to be pushed, got %+v", result)
+	}
+	if !desktop.EntryExists(date(t, "2024-03-04")) {
+		t.Error("expected the laptop's entry to be pulled")
+	}
+
+	// Nothing left to do
+	result, err = desktop.SyncToRemote(SyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Pushed || result.CommitMessage != "" {
+		t.Errorf("expected nothing to sync, got %+v", result)
+	}
+}
+
+func TestSyncToRemoteWithLocalChangesOutsideDocs(t *testing.T) {
+	laptop, desktop := cloneDiaries(t)
+
+	template := filepath.Join("templates", "entry.md")
+	if err := os.MkdirAll(filepath.Join(laptop.Dir, "templates"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(laptop.Dir, template), []byte("# {{date}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, laptop.Dir, "add", template)
+	gitRun(t, laptop.Dir, "commit", "-q", "-m", "add template")
+	writeEntries(t, laptop, map[string]string{"2024-03-04": "from the laptop\n"})
+	if _, err := laptop.SyncToRemote(SyncOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, desktop.Dir, "pull", "-q", "origin", "main")
+
+	writeEntries(t, laptop, map[string]string{"2024-03-05": "from the laptop\n"})
+	if _, err := laptop.SyncToRemote(SyncOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// An edited template isn't synced but mustn't stop the pull either
+	edited := "# {{date}}\n\n## Notes\n"
+	if err := os.WriteFile(filepath.Join(desktop.Dir, template), []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeEntries(t, desktop, map[string]string{"2024-03-06": "from the desktop\n"})
+	result, err := desktop.SyncToRemote(SyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Pushed {
+		t.Errorf("expected a commit to be pushed, got %+v", result)
+	}
+	if !desktop.EntryExists(date(t, "2024-03-05")) {
+		t.Error("expected the laptop's entry to be pulled")
+	}
+	if content, err := os.ReadFile(filepath.Join(desktop.Dir, template)); err != nil || string(content) != edited {
+		t.Errorf("expected the template edit to be kept, got %q (%v)", content, err)
+	}
+}
+
+func TestSyncToRemoteConflict(t *testing.T) {
+	laptop, desktop := cloneDiaries(t)
+	day := date(t, "2024-03-04")
+
+	writeEntries(t, laptop, map[string]string{"2024-03-04": "from the laptop\n"})
+	if _, err := laptop.SyncToRemote(SyncOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	writeEntries(t, desktop, map[string]string{"2024-03-04": "from the desktop\n"})
+	_, err := desktop.SyncToRemote(SyncOptions{})
+
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("expected a ConflictError, got %v", err)
+	}
+	expected := []string{"docs/2024/03/2024-03-04.md"}
+	if !reflect.DeepEqual(conflictErr.Files, expected) {
+		t.Errorf("expected conflicts in %v, got %v", expected, conflictErr.Files)
+	}
+
+	// The rebase is aborted, leaving the local commit in place
+	if content := readEntry(t, desktop, day); content != "from the desktop\n" {
+		t.Errorf("expected the local entry to be kept, got %q", content)
+	}
+	if status := gitRun(t, desktop.Dir, "status", "--porcelain"); status != "" {
+		t.Errorf("expected a clean working tree, got:\n%s", status)
+	}
+}
+
+func TestSyncToRemoteDryRun(t *testing.T) {
+	laptop, _ := cloneDiaries(t)
+
+	writeEntries(t, laptop, map[string]string{"2024-03-04": "from the laptop\n"})
+	result, err := laptop.SyncToRemote(SyncOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.CommitMessage, "docs/2024/03/") || result.Pushed {
+		t.Errorf("expected a commit message and no push, got %+v", result)
+	}
+
+	if log := gitRun(t, laptop.Dir, "log", "--oneline"); strings.Count(log, "\n") != 1 {
+		t.Errorf("expected no new commits, got:\n%s", log)
+	}
+	if status := gitRun(t, laptop.Dir, "status", "--porcelain"); !strings.HasPrefix(status, "??") {
+		t.Errorf("expected the entry to be left unstaged, got:\n%s", status)
+	}
+}
@@ -0,0 +1,88 @@
+package diary
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch calls onChange once entries under docs/ have stopped changing for
+// debounce, until ctx is cancelled. onChange is never called concurrently, so
+// it can sync the diary.
+func (d *Diary) Watch(ctx context.Context, debounce time.Duration, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	// fsnotify doesn't watch recursively, so add each directory, including
+	// the month directories created as entries are added
+	if err := watchTree(watcher, filepath.Join(d.Dir, "docs")); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := watchTree(watcher, event.Name); err != nil {
+						return err
+					}
+				}
+			}
+			if ignoredFile(event.Name) || event.Op == fsnotify.Chmod {
+				continue
+			}
+			timer.Reset(debounce)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+
+		case <-timer.C:
+			onChange()
+		}
+	}
+}
+
+func watchTree(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return watcher.Add(p)
+		}
+		return nil
+	})
+}
+
+// ignoredFile reports whether name is an editor's swap or backup file, which
+// change constantly while an entry is open
+func ignoredFile(name string) bool {
+	base := filepath.Base(name)
+	return strings.HasPrefix(base, ".") ||
+		strings.HasSuffix(base, "~") ||
+		strings.HasSuffix(base, ".swp") ||
+		strings.HasSuffix(base, ".swx") ||
+		base == "4913"
+}
@@ -0,0 +1,61 @@
+package diary
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchDebouncesChanges(t *testing.T) {
+	d := New(t.TempDir(), "origin", "main")
+	if err := os.MkdirAll(filepath.Join(d.Dir, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan struct{}, 10)
+	done := make(chan error)
+	go func() {
+		done <- d.Watch(ctx, 50*time.Millisecond, func() { changes <- struct{}{} })
+	}()
+
+	// Give the watcher time to start
+	time.Sleep(50 * time.Millisecond)
+
+	// Several writes, including one in a new month directory and a swap
+	// file, settle into a single change
+	writeEntries(t, d, map[string]string{"2024-03-04": "one\n"})
+	writeEntries(t, d, map[string]string{"2024-03-04": "two\n"})
+	if err := os.WriteFile(filepath.Join(filepath.Dir(d.EntryPathFor(date(t, "2024-03-04"))), ".2024-03-04.md.swp"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a change after writing an entry")
+	}
+
+	select {
+	case <-changes:
+		t.Fatal("expected the writes to be debounced into one change")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Entries in directories created after the watch started are seen too
+	writeEntries(t, d, map[string]string{"2024-04-01": "three\n"})
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a change after writing to the new directory")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected Watch to stop cleanly, got %v", err)
+	}
+}
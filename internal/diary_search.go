@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/thomasgormley/dev-cli-go/internal/diary"
+	"github.com/thomasgormley/dev-cli-go/internal/editor"
+	"github.com/urfave/cli/v2"
+)
+
+func handleDiarySearch(stdout, stderr io.Writer, d *diary.Diary) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if err := requireDiary(d); err != nil {
+			return err
+		}
+
+		query, err := searchQuery(c)
+		if err != nil {
+			return err
+		}
+
+		matches, err := d.Search(query)
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+		if len(matches) == 0 {
+			return cli.Exit("No matches", 1)
+		}
+
+		renderMatches(stdout, d.Dir, matches, query.Context > 0)
+
+		if c.Bool("no-open") || !isInteractive() {
+			return nil
+		}
+		return openMatch(c, stdout, stderr, matches)
+	}
+}
+
+// searchQuery builds the query from the arguments, #tag words become tag
+// filters and the rest are searched for as a phrase, or a regex with --regex.
+func searchQuery(c *cli.Context) (diary.Query, error) {
+	words, tags := diary.SplitTags(c.Args().Slice())
+	query := diary.Query{Tags: tags, Context: c.Int("context")}
+
+	if len(words) == 0 && len(tags) == 0 {
+		return diary.Query{}, cli.Exit("Nothing to search for, pass a phrase, regex or #tag", 1)
+	}
+	if query.Context < 0 {
+		return diary.Query{}, cli.Exit(fmt.Sprintf("Invalid --context %d, it can't be negative", query.Context), 1)
+	}
+
+	if phrase := strings.Join(words, " "); phrase != "" {
+		if c.Bool("regex") {
+			re, err := regexp.Compile(phrase)
+			if err != nil {
+				return diary.Query{}, cli.Exit(fmt.Sprintf("Invalid regex: %v", err), 1)
+			}
+			query.Pattern = re
+		} else {
+			query.Pattern = diary.PhrasePattern(phrase)
+		}
+	}
+
+	for name, field := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+		s := c.String(name)
+		if s == "" {
+			continue
+		}
+		t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+		if err != nil {
+			return diary.Query{}, cli.Exit(fmt.Sprintf("Invalid --%s %q, expected YYYY-MM-DD", name, s), 1)
+		}
+		*field = t
+	}
+
+	return query, nil
+}
+
+// renderMatches prints each match grep style, with the matching line marked
+// and separators between matches when there's context.
+func renderMatches(w io.Writer, root string, matches []diary.Match, separate bool) {
+	for i, match := range matches {
+		if i > 0 && separate {
+			fmt.Fprintln(w, "--")
+		}
+		fmt.Fprintf(w, "%s  %s:%d\n", match.Entry.Date.Format(time.DateOnly), relativeTo(root, match.Entry.Path), match.Line)
+
+		first := match.Line - len(match.Before)
+		for j, line := range match.Before {
+			fmt.Fprintf(w, "  %4d  %s\n", first+j, line)
+		}
+		fmt.Fprintf(w, "> %4d  %s\n", match.Line, match.Text)
+		for j, line := range match.After {
+			fmt.Fprintf(w, "  %4d  %s\n", match.Line+1+j, line)
+		}
+	}
+}
+
+// openMatch asks which match to open, then opens it in $EDITOR at the
+// matching line.
+func openMatch(c *cli.Context, stdout, stderr io.Writer, matches []diary.Match) error {
+	editorPath, editorArgs, ok := editor.Lookup()
+	if !ok {
+		return nil
+	}
+
+	options := make([]string, len(matches))
+	for i, match := range matches {
+		options[i] = fmt.Sprintf("%s:%d  %s", match.Entry.Date.Format(time.DateOnly), match.Line, strings.TrimSpace(match.Text))
+	}
+
+	var selected int
+	prompt := &survey.Select{
+		Message:  "Open match",
+		Options:  options,
+		PageSize: 16,
+	}
+	if err := survey.AskOne(prompt, &selected); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	match := matches[selected]
+	target := fmt.Sprintf("%s:%d:1", match.Entry.Path, match.Line)
+	cmd := prepareCmd(c.Context, os.Stdin, stdout, stderr, editorPath, append(editorArgs, target)...)
+	if err := cmd.Start(); err != nil {
+		return cli.Exit(err, 1)
+	}
+	return nil
+}
+
+func relativeTo(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/thomasgormley/dev-cli-go/internal/diary"
+	"github.com/urfave/cli/v2"
+)
+
+func handleDiarySummary(stdout, stderr io.Writer, d *diary.Diary) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if err := requireDiary(d); err != nil {
+			return err
+		}
+
+		from, until, err := summaryRange(c, time.Now())
+		if err != nil {
+			return err
+		}
+
+		summary, err := d.Summarize(from, until)
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		output := c.String("output")
+		if output == "" {
+			fmt.Fprint(stdout, summary.Markdown())
+			return nil
+		}
+
+		if err := os.WriteFile(output, []byte(summary.Markdown()), 0644); err != nil {
+			return cli.Exit(err, 1)
+		}
+		fmt.Fprintf(stdout, "Wrote summary of %d entries to %s ✅\n", len(summary.Entries), output)
+		return nil
+	}
+}
+
+// summaryRange returns the days to summarise, this week unless --month or
+// --range say otherwise.
+func summaryRange(c *cli.Context, now time.Time) (from, until time.Time, err error) {
+	set := 0
+	for _, name := range []string{"week", "month", "range"} {
+		if c.IsSet(name) {
+			set++
+		}
+	}
+	if set > 1 {
+		return from, until, cli.Exit("Only one of --week, --month and --range can be used", 1)
+	}
+
+	switch {
+	case c.Bool("month"):
+		from, until = diary.MonthOf(now)
+	case c.IsSet("range"):
+		first, last, ok := strings.Cut(c.String("range"), "..")
+		if !ok {
+			return from, until, cli.Exit(fmt.Sprintf("Invalid --range %q, expected YYYY-MM-DD..YYYY-MM-DD", c.String("range")), 1)
+		}
+		if from, err = time.ParseInLocation(time.DateOnly, first, time.Local); err != nil {
+			return from, until, cli.Exit(fmt.Sprintf("Invalid --range start %q, expected YYYY-MM-DD", first), 1)
+		}
+		if until, err = time.ParseInLocation(time.DateOnly, last, time.Local); err != nil {
+			return from, until, cli.Exit(fmt.Sprintf("Invalid --range end %q, expected YYYY-MM-DD", last), 1)
+		}
+		if until.Before(from) {
+			return from, until, cli.Exit("--range ends before it starts", 1)
+		}
+	default:
+		from, until = diary.WeekOf(now)
+	}
+	return from, until, nil
+}
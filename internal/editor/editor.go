@@ -1,7 +1,9 @@
 package editor
 
 import (
+	"errors"
 	"os"
+	"os/exec"
 	"strings"
 )
 
@@ -16,3 +18,41 @@ func Lookup() (editor string, args []string, found bool) {
 	}
 	return parts[0], parts[1:], found
 }
+
+// Edit writes content to a temporary file named after pattern (see
+// os.CreateTemp), opens it in $EDITOR and returns the content once the editor
+// exits. GUI editors need their wait flag set, e.g. EDITOR="code --wait".
+func Edit(pattern, content string) (string, error) {
+	editorPath, editorArgs, ok := Lookup()
+	if !ok {
+		return "", errors.New("$EDITOR not set")
+	}
+
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command(editorPath, append(editorArgs, file.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(edited), nil
+}
@@ -0,0 +1,454 @@
+package gh
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thomasgormley/dev-cli-go/internal/git"
+)
+
+const DefaultGraphQLEndpoint = "https://api.github.com/graphql"
+
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("not found")
+)
+
+// APIError is returned when the GitHub API responds with a non-2xx status or
+// a GraphQL errors payload.
+type APIError struct {
+	StatusCode int
+	Message    string
+	Errors     []GraphQLError
+}
+
+type GraphQLError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	// Path holds field names and, within lists, indices
+	Path []any `json:"path"`
+}
+
+func (e *APIError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("github api: %d %s", e.StatusCode, e.Message)
+	}
+
+	var messages []string
+	for _, err := range e.Errors {
+		messages = append(messages, err.Message)
+	}
+	return fmt.Sprintf("github api: %s", strings.Join(messages, "; "))
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		if e.StatusCode == http.StatusNotFound {
+			return true
+		}
+		for _, err := range e.Errors {
+			if err.Type == "NOT_FOUND" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// apiClient implements GitHubClienter against the GraphQL API directly,
+// rather than shelling out to gh for every call.
+type apiClient struct {
+	Stderr io.Writer
+	Stdout io.Writer
+
+	Endpoint   string
+	HTTPClient *http.Client
+
+	// Token is looked up with LookupToken on the first request when empty,
+	// so commands that never call the API don't need one
+	Token       string
+	LookupToken func() (string, error)
+
+	// Owner, Repo and Branch are resolved from the git checkout when empty
+	Owner  string
+	Repo   string
+	Branch string
+
+	OpenBrowser func(url string) error
+}
+
+func NewAPIClient(stderr, stdout io.Writer, token string) *apiClient {
+	return &apiClient{
+		Stderr:      stderr,
+		Stdout:      stdout,
+		Endpoint:    DefaultGraphQLEndpoint,
+		Token:       token,
+		LookupToken: LookupToken,
+		HTTPClient:  &http.Client{Timeout: 30 * time.Second},
+		OpenBrowser: openBrowser,
+	}
+}
+
+func (a *apiClient) AuthStatus() error {
+	var resp struct {
+		Viewer struct {
+			Login string `json:"login"`
+		} `json:"viewer"`
+	}
+	return a.graphql(context.Background(), `query { viewer { login } }`, nil, &resp)
+}
+
+func (a *apiClient) CreatePR(title, body, base string, draft bool) error {
+	ctx := context.Background()
+	owner, repo, branch, err := a.resolveRepo()
+	if err != nil {
+		return err
+	}
+
+	var repoResp struct {
+		Repository struct {
+			ID               string `json:"id"`
+			DefaultBranchRef struct {
+				Name string `json:"name"`
+			} `json:"defaultBranchRef"`
+		} `json:"repository"`
+	}
+	err = a.graphql(ctx, `query($owner: String!, $name: String!) {
+		repository(owner: $owner, name: $name) { id defaultBranchRef { name } }
+	}`, map[string]any{"owner": owner, "name": repo}, &repoResp)
+	if err != nil {
+		return err
+	}
+
+	if base == "" {
+		base = repoResp.Repository.DefaultBranchRef.Name
+	}
+
+	var createResp struct {
+		CreatePullRequest struct {
+			PullRequest struct {
+				URL string `json:"url"`
+			} `json:"pullRequest"`
+		} `json:"createPullRequest"`
+	}
+	err = a.graphql(ctx, `mutation($input: CreatePullRequestInput!) {
+		createPullRequest(input: $input) { pullRequest { url } }
+	}`, map[string]any{"input": map[string]any{
+		"repositoryId": repoResp.Repository.ID,
+		"baseRefName":  base,
+		"headRefName":  branch,
+		"title":        title,
+		"body":         body,
+		"draft":        draft,
+	}}, &createResp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(a.Stdout, createResp.CreatePullRequest.PullRequest.URL)
+	return nil
+}
+
+func (a *apiClient) ViewPR(identifier string) error {
+	url := identifier
+	if !strings.HasPrefix(identifier, "https://") {
+		pr, err := a.findPR(context.Background(), identifier)
+		if err != nil {
+			return err
+		}
+		url = pr.URL
+	}
+
+	fmt.Fprintf(a.Stderr, "Opening %s in your browser.\n", url)
+	return a.OpenBrowser(url)
+}
+
+func (a *apiClient) PRStatus(identifier string) (PRStatusResponse, error) {
+	pr, err := a.findPR(context.Background(), identifier)
+	if err != nil {
+		return PRStatusResponse{}, err
+	}
+	return PRStatusResponse{CurrentBranch: pr}, nil
+}
+
+func (a *apiClient) MergePR(strategy MergeStrategy) error {
+	methods := map[MergeStrategy]string{
+		MergeSquash: "SQUASH",
+		MergeCommit: "MERGE",
+		MergeRebase: "REBASE",
+	}
+	method, ok := methods[strategy]
+	if !ok {
+		return fmt.Errorf("missing or invalid merge strategy %s", strategy)
+	}
+
+	ctx := context.Background()
+	pr, err := a.findPR(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		MergePullRequest struct {
+			PullRequest struct {
+				Merged bool `json:"merged"`
+			} `json:"pullRequest"`
+		} `json:"mergePullRequest"`
+	}
+	return a.graphql(ctx, `mutation($input: MergePullRequestInput!) {
+		mergePullRequest(input: $input) { pullRequest { merged } }
+	}`, map[string]any{"input": map[string]any{
+		"pullRequestId": pr.ID,
+		"mergeMethod":   method,
+	}}, &resp)
+}
+
+const pullRequestFragment = `fragment pr on PullRequest {
+	id number title url closed isDraft updatedAt
+	additions deletions changedFiles
+	baseRefName headRefName mergeStateStatus mergeable
+	comments(last: 100) {
+		nodes { id author { login } body createdAt includesCreatedEdit url }
+	}
+	files(first: 100) { nodes { path additions deletions } }
+	allCommits: commits(last: 100) { nodes { commit { oid authoredDate } } }
+	lastCommit: commits(last: 1) {
+		nodes { commit { statusCheckRollup { contexts(first: 100) { nodes {
+			__typename
+			... on CheckRun { name status conclusion detailsUrl startedAt completedAt }
+			... on StatusContext { context state targetUrl createdAt }
+		} } } } }
+	}
+}`
+
+type pullRequestNode struct {
+	PullRequest
+	Comments struct {
+		Nodes []PRComment `json:"nodes"`
+	} `json:"comments"`
+	Files struct {
+		Nodes []PRFile `json:"nodes"`
+	} `json:"files"`
+	AllCommits struct {
+		Nodes []struct {
+			Commit PRCommit `json:"commit"`
+		} `json:"nodes"`
+	} `json:"allCommits"`
+	LastCommit struct {
+		Nodes []struct {
+			Commit struct {
+				StatusCheckRollup *struct {
+					Contexts struct {
+						Nodes []struct {
+							StatusCheckRollup
+							CreatedAt time.Time `json:"createdAt"`
+						} `json:"nodes"`
+					} `json:"contexts"`
+				} `json:"statusCheckRollup"`
+			} `json:"commit"`
+		} `json:"nodes"`
+	} `json:"lastCommit"`
+}
+
+// toPullRequest flattens the GraphQL connections into the same shape
+// `gh pr status --json` produces.
+func (n pullRequestNode) toPullRequest() PullRequest {
+	pr := n.PullRequest
+	pr.Comments = n.Comments.Nodes
+	pr.Files = n.Files.Nodes
+
+	pr.Commits = nil
+	for _, node := range n.AllCommits.Nodes {
+		pr.Commits = append(pr.Commits, node.Commit)
+	}
+
+	pr.StatusCheckRollup = nil
+	for _, node := range n.LastCommit.Nodes {
+		if node.Commit.StatusCheckRollup == nil {
+			continue
+		}
+		for _, check := range node.Commit.StatusCheckRollup.Contexts.Nodes {
+			if check.StartedAt.IsZero() {
+				check.StartedAt = check.CreatedAt
+			}
+			pr.StatusCheckRollup = append(pr.StatusCheckRollup, check.StatusCheckRollup)
+		}
+	}
+
+	return pr
+}
+
+// findPR looks up a pull request by number, or by head branch, defaulting to
+// the most recent pull request for the current branch.
+func (a *apiClient) findPR(ctx context.Context, identifier string) (PullRequest, error) {
+	owner, repo, branch, err := a.resolveRepo()
+	if err != nil {
+		return PullRequest{}, err
+	}
+
+	if number, err := strconv.Atoi(strings.TrimPrefix(identifier, "#")); err == nil {
+		var resp struct {
+			Repository struct {
+				PullRequest *pullRequestNode `json:"pullRequest"`
+			} `json:"repository"`
+		}
+		err := a.graphql(ctx, `query($owner: String!, $name: String!, $number: Int!) {
+			repository(owner: $owner, name: $name) { pullRequest(number: $number) { ...pr } }
+		}
+		`+pullRequestFragment, map[string]any{"owner": owner, "name": repo, "number": number}, &resp)
+		if err != nil {
+			return PullRequest{}, err
+		}
+		if resp.Repository.PullRequest == nil {
+			return PullRequest{}, ErrNoPullRequest
+		}
+		return resp.Repository.PullRequest.toPullRequest(), nil
+	}
+
+	if identifier != "" {
+		branch = identifier
+	}
+
+	var resp struct {
+		Repository struct {
+			PullRequests struct {
+				Nodes []pullRequestNode `json:"nodes"`
+			} `json:"pullRequests"`
+		} `json:"repository"`
+	}
+	err = a.graphql(ctx, `query($owner: String!, $name: String!, $branch: String!) {
+		repository(owner: $owner, name: $name) {
+			pullRequests(headRefName: $branch, first: 1, orderBy: {field: CREATED_AT, direction: DESC}) {
+				nodes { ...pr }
+			}
+		}
+	}
+	`+pullRequestFragment, map[string]any{"owner": owner, "name": repo, "branch": branch}, &resp)
+	if err != nil {
+		return PullRequest{}, err
+	}
+
+	if len(resp.Repository.PullRequests.Nodes) == 0 {
+		return PullRequest{}, ErrNoPullRequest
+	}
+	return resp.Repository.PullRequests.Nodes[0].toPullRequest(), nil
+}
+
+func (a *apiClient) resolveRepo() (owner, repo, branch string, err error) {
+	if a.Owner == "" || a.Repo == "" {
+		url, err := git.RemoteURL("origin")
+		if err != nil {
+			return "", "", "", fmt.Errorf("failed to find the origin remote: %w", err)
+		}
+		a.Owner, a.Repo, err = parseRepoURL(url)
+		if err != nil {
+			return "", "", "", err
+		}
+	}
+
+	if a.Branch == "" {
+		a.Branch, err = git.CurrentBranch()
+		if err != nil {
+			return "", "", "", fmt.Errorf("failed to find the current branch: %w", err)
+		}
+	}
+
+	return a.Owner, a.Repo, a.Branch, nil
+}
+
+var repoURLPattern = regexp.MustCompile(`github\.com[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// parseRepoURL extracts the owner and repository name from an https or ssh
+// GitHub remote URL.
+func parseRepoURL(url string) (owner, repo string, err error) {
+	matches := repoURLPattern.FindStringSubmatch(url)
+	if matches == nil {
+		return "", "", fmt.Errorf("remote %q is not a GitHub repository", url)
+	}
+	return matches[1], matches[2], nil
+}
+
+func (a *apiClient) graphql(ctx context.Context, query string, variables map[string]any, out any) error {
+	if a.Token == "" {
+		token, err := a.LookupToken()
+		if err != nil {
+			return err
+		}
+		a.Token = token
+	}
+
+	payload, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+a.Token)
+	req.Header.Set("Content-Type", "application/json")
+	// mergeStateStatus is still behind a preview
+	req.Header.Set("Accept", "application/vnd.github.merge-info-preview+json")
+
+	resp, err := a.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("github api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("github api: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		var errResp struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &errResp) == nil && errResp.Message != "" {
+			apiErr.Message = errResp.Message
+		}
+		return apiErr
+	}
+
+	var gqlResp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []GraphQLError  `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &gqlResp); err != nil {
+		return fmt.Errorf("github api: invalid response: %w", err)
+	}
+
+	if len(gqlResp.Errors) > 0 {
+		return &APIError{StatusCode: resp.StatusCode, Errors: gqlResp.Errors}
+	}
+
+	return json.Unmarshal(gqlResp.Data, out)
+}
+
+func openBrowser(url string) error {
+	name := "xdg-open"
+	switch runtime.GOOS {
+	case "darwin":
+		name = "open"
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Run()
+	}
+	return exec.Command(name, url).Run()
+}
@@ -0,0 +1,293 @@
+package gh
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type graphqlRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+// newTestAPIClient returns a client pointed at a local server standing in for
+// api.github.com, which answers every request with handler.
+func newTestAPIClient(t *testing.T, handler func(t *testing.T, req graphqlRequest) (int, string)) (*apiClient, *bytes.Buffer) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "bearer test-token" {
+			t.Errorf("expected bearer token, got %q", got)
+		}
+
+		var req graphqlRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+
+		status, body := handler(t, req)
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	var stdout bytes.Buffer
+	client := NewAPIClient(io.Discard, &stdout, "test-token")
+	client.Endpoint = server.URL
+	client.Owner = "thomasgormley"
+	client.Repo = "dev-cli-go"
+	client.Branch = "ABC-123-some-description"
+	return client, &stdout
+}
+
+func TestAPIClientLooksUpTokenOnFirstRequest(t *testing.T) {
+	client, _ := newTestAPIClient(t, func(t *testing.T, req graphqlRequest) (int, string) {
+		return http.StatusOK, `{"data": {"viewer": {"login": "thomasgormley"}}}`
+	})
+	client.Token = ""
+
+	lookups := 0
+	client.LookupToken = func() (string, error) {
+		lookups++
+		return "", ErrNoToken
+	}
+	if err := client.AuthStatus(); !errors.Is(err, ErrNoToken) {
+		t.Errorf("expected ErrNoToken, got %v", err)
+	}
+
+	client.LookupToken = func() (string, error) {
+		lookups++
+		return "test-token", nil
+	}
+	for i := 0; i < 2; i++ {
+		if err := client.AuthStatus(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if lookups != 2 {
+		t.Errorf("expected the token to be looked up until found, then kept, got %d lookups", lookups)
+	}
+}
+
+func TestAPIClientAuthStatus(t *testing.T) {
+	client, _ := newTestAPIClient(t, func(t *testing.T, req graphqlRequest) (int, string) {
+		return http.StatusOK, `{"data": {"viewer": {"login": "thomasgormley"}}}`
+	})
+	if err := client.AuthStatus(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	client, _ = newTestAPIClient(t, func(t *testing.T, req graphqlRequest) (int, string) {
+		return http.StatusUnauthorized, `{"message": "Bad credentials"}`
+	})
+	err := client.AuthStatus()
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "Bad credentials" {
+		t.Errorf("expected APIError with message, got %#v", err)
+	}
+}
+
+func TestAPIClientPRStatus(t *testing.T) {
+	client, _ := newTestAPIClient(t, func(t *testing.T, req graphqlRequest) (int, string) {
+		if req.Variables["branch"] != "ABC-123-some-description" {
+			t.Errorf("expected the current branch, got %v", req.Variables["branch"])
+		}
+		return http.StatusOK, `{"data": {"repository": {"pullRequests": {"nodes": [{
+			"id": "PR_1",
+			"number": 42,
+			"title": "ABC-123: some description",
+			"mergeStateStatus": "BLOCKED",
+			"comments": {"nodes": [{"id": "C_1", "body": "LGTM"}]},
+			"files": {"nodes": [{"path": "main.go", "additions": 1, "deletions": 2}]},
+			"allCommits": {"nodes": [{"commit": {"oid": "abc"}}, {"commit": {"oid": "def"}}]},
+			"lastCommit": {"nodes": [{"commit": {"statusCheckRollup": {"contexts": {"nodes": [
+				{"__typename": "CheckRun", "name": "build", "status": "COMPLETED", "conclusion": "SUCCESS", "startedAt": "2026-10-17T09:00:00Z"},
+				{"__typename": "StatusContext", "context": "ci/deploy", "state": "PENDING", "createdAt": "2026-10-17T09:01:00Z"}
+			]}}}}]}
+		}]}}}}`
+	})
+
+	status, err := client.PRStatus("")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	pr := status.CurrentBranch
+	if pr.ID != "PR_1" || pr.Number != 42 || pr.MergeStateStatus != BLOCKED {
+		t.Errorf("unexpected pull request %+v", pr)
+	}
+	if len(pr.Comments) != 1 || len(pr.Files) != 1 || len(pr.Commits) != 2 {
+		t.Errorf("expected 1 comment, 1 file and 2 commits, got %d, %d and %d", len(pr.Comments), len(pr.Files), len(pr.Commits))
+	}
+	if len(pr.StatusCheckRollup) != 2 {
+		t.Fatalf("expected 2 checks, got %d", len(pr.StatusCheckRollup))
+	}
+	if check := pr.StatusCheckRollup[1]; check.DisplayName() != "ci/deploy" || check.StartedAt.IsZero() {
+		t.Errorf("expected status context with a start time, got %+v", check)
+	}
+}
+
+func TestAPIClientPRStatusNoPullRequest(t *testing.T) {
+	client, _ := newTestAPIClient(t, func(t *testing.T, req graphqlRequest) (int, string) {
+		return http.StatusOK, `{"data": {"repository": {"pullRequests": {"nodes": []}}}}`
+	})
+
+	if _, err := client.PRStatus(""); !errors.Is(err, ErrNoPullRequest) {
+		t.Fatalf("expected ErrNoPullRequest, got %v", err)
+	}
+}
+
+func TestAPIClientGraphQLErrors(t *testing.T) {
+	client, _ := newTestAPIClient(t, func(t *testing.T, req graphqlRequest) (int, string) {
+		return http.StatusOK, `{"data": null, "errors": [{"type": "NOT_FOUND", "message": "Could not resolve to a PullRequest with the number of 7."}]}`
+	})
+
+	_, err := client.PRStatus("#7")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Could not resolve") {
+		t.Errorf("expected the GraphQL message in the error, got %q", err.Error())
+	}
+
+	// Paths into lists include the index
+	client, _ = newTestAPIClient(t, func(t *testing.T, req graphqlRequest) (int, string) {
+		return http.StatusOK, `{"data": null, "errors": [{"type": "FORBIDDEN", "message": "Resource not accessible by integration", "path": ["repository", "pullRequests", "nodes", 0, "files"]}]}`
+	})
+
+	_, err = client.PRStatus("#7")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || len(apiErr.Errors) != 1 {
+		t.Fatalf("expected an *APIError with the GraphQL error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Resource not accessible") {
+		t.Errorf("expected the GraphQL message in the error, got %q", err.Error())
+	}
+	if path := apiErr.Errors[0].Path; len(path) != 5 || path[3] != float64(0) {
+		t.Errorf("expected the path with its list index, got %v", path)
+	}
+}
+
+func TestAPIClientMergePR(t *testing.T) {
+	var mergeInput map[string]any
+	client, _ := newTestAPIClient(t, func(t *testing.T, req graphqlRequest) (int, string) {
+		if strings.Contains(req.Query, "mergePullRequest") {
+			mergeInput = req.Variables["input"].(map[string]any)
+			return http.StatusOK, `{"data": {"mergePullRequest": {"pullRequest": {"merged": true}}}}`
+		}
+		return http.StatusOK, `{"data": {"repository": {"pullRequests": {"nodes": [{"id": "PR_1"}]}}}}`
+	})
+
+	if err := client.MergePR(MergeSquash); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if mergeInput["pullRequestId"] != "PR_1" || mergeInput["mergeMethod"] != "SQUASH" {
+		t.Errorf("unexpected merge input %v", mergeInput)
+	}
+
+	if err := client.MergePR("fast-forward"); err == nil {
+		t.Errorf("expected an error for an invalid strategy")
+	}
+}
+
+func TestAPIClientCreatePR(t *testing.T) {
+	var createInput map[string]any
+	client, stdout := newTestAPIClient(t, func(t *testing.T, req graphqlRequest) (int, string) {
+		if strings.Contains(req.Query, "createPullRequest") {
+			createInput = req.Variables["input"].(map[string]any)
+			return http.StatusOK, `{"data": {"createPullRequest": {"pullRequest": {"url": "https://github.com/thomasgormley/dev-cli-go/pull/43"}}}}`
+		}
+		return http.StatusOK, `{"data": {"repository": {"id": "R_1", "defaultBranchRef": {"name": "main"}}}}`
+	})
+
+	if err := client.CreatePR("Title", "Body", "", true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := map[string]any{
+		"repositoryId": "R_1",
+		"baseRefName":  "main",
+		"headRefName":  "ABC-123-some-description",
+		"title":        "Title",
+		"body":         "Body",
+		"draft":        true,
+	}
+	for key, value := range expected {
+		if createInput[key] != value {
+			t.Errorf("expected input %s to be %v, got %v", key, value, createInput[key])
+		}
+	}
+	if !strings.Contains(stdout.String(), "/pull/43") {
+		t.Errorf("expected the pull request URL to be printed, got %q", stdout.String())
+	}
+}
+
+func TestParseRepoURL(t *testing.T) {
+	tests := []struct {
+		input string
+		owner string
+		repo  string
+	}{
+		{"https://github.com/thomasgormley/dev-cli-go.git", "thomasgormley", "dev-cli-go"},
+		{"https://github.com/thomasgormley/dev-cli-go", "thomasgormley", "dev-cli-go"},
+		{"git@github.com:thomasgormley/dev-cli-go.git", "thomasgormley", "dev-cli-go"},
+		{"ssh://git@github.com/thomasgormley/dev-cli-go.git", "thomasgormley", "dev-cli-go"},
+	}
+	for _, test := range tests {
+		owner, repo, err := parseRepoURL(test.input)
+		if err != nil || owner != test.owner || repo != test.repo {
+			t.Errorf("For input %q, expected %s/%s, got %s/%s (%v)", test.input, test.owner, test.repo, owner, repo, err)
+		}
+	}
+
+	if _, _, err := parseRepoURL("https://gitlab.com/o/r.git"); err == nil {
+		t.Errorf("expected an error for a non-GitHub remote")
+	}
+}
+
+func TestLookupToken(t *testing.T) {
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+
+	dir := t.TempDir()
+	t.Setenv("GH_CONFIG_DIR", dir)
+
+	if _, err := LookupToken(); !errors.Is(err, ErrNoToken) {
+		t.Fatalf("expected ErrNoToken without hosts.yml, got %v", err)
+	}
+
+	hosts := `github.example.com:
+    oauth_token: wrong-host
+github.com:
+    users:
+        thomasgormley:
+            oauth_token: nested
+    oauth_token: gho_from_hosts
+    user: thomasgormley
+`
+	if err := os.WriteFile(filepath.Join(dir, "hosts.yml"), []byte(hosts), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := LookupToken()
+	if err != nil || token != "gho_from_hosts" {
+		t.Errorf("expected token from hosts.yml, got %q (%v)", token, err)
+	}
+
+	t.Setenv("GITHUB_TOKEN", "from-env")
+	if token, _ := LookupToken(); token != "from-env" {
+		t.Errorf("expected GITHUB_TOKEN to take precedence, got %q", token)
+	}
+}
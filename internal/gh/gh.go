@@ -4,10 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os/exec"
 	"strings"
+	"time"
+)
+
+var (
+	ErrNoPullRequest = errors.New("no pull request available")
+
+	// ErrNotLoggedIn is returned by AuthStatus when gh has no credentials
+	ErrNotLoggedIn = errors.New("gh is not logged in")
 )
 
 type GitHubClienter interface {
@@ -28,8 +37,12 @@ func (g *ghClient) AuthStatus() error {
 	cmd := g.prepareCmd("gh", "auth", "status")
 	cmd.Stdout = nil // gh auth status writes to stdout, we don't need to see it
 	err := cmd.Run()
-	if err != nil {
-		return err
+
+	// gh auth status exits non-zero when there's no login, other errors,
+	// e.g. gh not being installed, are returned as they are
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("%w: %v", ErrNotLoggedIn, err)
 	}
 	return err
 }
@@ -103,64 +116,159 @@ const (
 	UNSTABLE MergeStateStatus = "UNSTABLE"
 )
 
+// Description returns a human explanation of the merge state.
+func (s MergeStateStatus) Description() string {
+	switch s {
+	case BEHIND:
+		return "The head branch is out of date with the base branch"
+	case BLOCKED:
+		return "Merging is blocked, e.g. by required reviews or checks"
+	case CLEAN:
+		return "Ready to merge, all checks are passing"
+	case DIRTY:
+		return "There are merge conflicts with the base branch"
+	case DRAFT:
+		return "The pull request is still a draft"
+	case HAS_HOOKS:
+		return "Ready to merge, pre-receive hooks will run on merge"
+	case UNSTABLE:
+		return "Mergeable, but some checks are not passing"
+	default:
+		return "GitHub hasn't determined the merge state yet"
+	}
+}
+
 type MergeStrategy string
 
 const (
-	// The head ref is out of date.
+	// Squash the commits into one and merge it into the base branch.
 	MergeSquash MergeStrategy = "squash"
-	// The merge is blocked.
+	// Merge the commits with the base branch via a merge commit.
 	MergeCommit MergeStrategy = "merge"
-	// Mergeable and passing commit status.
+	// Rebase the commits onto the base branch.
 	MergeRebase MergeStrategy = "rebase"
 )
 
+var MergeStrategies = []MergeStrategy{MergeSquash, MergeCommit, MergeRebase}
+
+// ParseMergeStrategy returns the MergeStrategy matching s.
+func ParseMergeStrategy(s string) (MergeStrategy, error) {
+	for _, strategy := range MergeStrategies {
+		if string(strategy) == s {
+			return strategy, nil
+		}
+	}
+	return "", fmt.Errorf("invalid merge strategy %q, expected one of squash, merge or rebase", s)
+}
+
 type PRStatusResponse struct {
-	CurrentBranch struct {
-		Closed       bool   `json:"closed"`
-		Additions    int    `json:"additions"`
-		BaseRefName  string `json:"baseRefName"`
-		ChangedFiles int    `json:"changedFiles"`
-		HeadRefName  string `json:"headRefName"`
-		IsDraft      bool   `json:"isDraft"`
-		Comments     []struct {
-			ID     string `json:"id"`
-			Author struct {
-				Login string `json:"login"`
-			} `json:"author"`
-			Body         string `json:"body"`
-			CreatedAt    string `json:"createdAt"`
-			IncludesEdit bool   `json:"includesCreatedEdit"`
-			URL          string `json:"url"`
-		} `json:"comments"`
-		Commits []struct {
-			AuthoredDate string `json:"authoredDate"`
-			OID          string `json:"oid"`
-		} `json:"commits"`
-		Files []struct {
-			Path      string `json:"path"`
-			Additions int    `json:"additions"`
-			Deletions int    `json:"deletions"`
-		} `json:"files"`
-		MergeStateStatus  MergeStateStatus    `json:"mergeStateStatus"`
-		Mergeable         string              `json:"mergeable"`
-		StatusCheckRollup []StatusCheckRollup `json:"statusCheckRollup"`
-		Title             string              `json:"title"`
-		UpdatedAt         string              `json:"updatedAt"`
-		URL               string              `json:"url"`
-	} `json:"currentBranch"`
+	CurrentBranch PullRequest `json:"currentBranch"`
+}
+
+type PullRequest struct {
+	ID                string              `json:"id"`
+	Closed            bool                `json:"closed"`
+	Additions         int                 `json:"additions"`
+	Deletions         int                 `json:"deletions"`
+	Number            int                 `json:"number"`
+	BaseRefName       string              `json:"baseRefName"`
+	ChangedFiles      int                 `json:"changedFiles"`
+	HeadRefName       string              `json:"headRefName"`
+	IsDraft           bool                `json:"isDraft"`
+	Comments          []PRComment         `json:"comments"`
+	Commits           []PRCommit          `json:"commits"`
+	Files             []PRFile            `json:"files"`
+	MergeStateStatus  MergeStateStatus    `json:"mergeStateStatus"`
+	Mergeable         string              `json:"mergeable"`
+	StatusCheckRollup []StatusCheckRollup `json:"statusCheckRollup"`
+	Title             string              `json:"title"`
+	UpdatedAt         string              `json:"updatedAt"`
+	URL               string              `json:"url"`
+}
+
+type PRComment struct {
+	ID     string `json:"id"`
+	Author struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Body         string `json:"body"`
+	CreatedAt    string `json:"createdAt"`
+	IncludesEdit bool   `json:"includesCreatedEdit"`
+	URL          string `json:"url"`
 }
 
+type PRCommit struct {
+	AuthoredDate string `json:"authoredDate"`
+	OID          string `json:"oid"`
+}
+
+type PRFile struct {
+	Path      string `json:"path"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// StatusCheckRollup is either a check run (Name, Status, Conclusion) or a
+// commit status (Context, State), gh returns both in the same list.
 type StatusCheckRollup struct {
-	Name       string `json:"name"`
-	Context    string `json:"context"`
-	Conclusion string `json:"conclusion"`
-	DetailsURL string `json:"detailsUrl"`
-	Status     string `json:"status"`
-	State      string `json:"state"`
+	Name        string    `json:"name"`
+	Context     string    `json:"context"`
+	Conclusion  string    `json:"conclusion"`
+	DetailsURL  string    `json:"detailsUrl"`
+	TargetURL   string    `json:"targetUrl"`
+	Status      string    `json:"status"`
+	State       string    `json:"state"`
+	StartedAt   time.Time `json:"startedAt"`
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+type CheckState string
+
+const (
+	CheckPending CheckState = "pending"
+	CheckPass    CheckState = "pass"
+	CheckFail    CheckState = "fail"
+	CheckSkip    CheckState = "skip"
+)
+
+// DisplayName returns the check run name or the status context.
+func (c StatusCheckRollup) DisplayName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Context
+}
+
+// URL returns the link to the check's details, if any.
+func (c StatusCheckRollup) URL() string {
+	if c.DetailsURL != "" {
+		return c.DetailsURL
+	}
+	return c.TargetURL
+}
+
+// Bucket collapses the check run conclusion or commit status state into a
+// single pass/fail/pending/skip state, matching `gh pr checks`.
+func (c StatusCheckRollup) Bucket() CheckState {
+	result := c.Conclusion
+	if c.State != "" {
+		result = c.State
+	}
+
+	switch result {
+	case "SUCCESS":
+		return CheckPass
+	case "FAILURE", "ERROR", "CANCELLED", "TIMED_OUT", "ACTION_REQUIRED", "STARTUP_FAILURE":
+		return CheckFail
+	case "SKIPPED", "NEUTRAL":
+		return CheckSkip
+	default:
+		return CheckPending
+	}
 }
 
 var jsonFields = []string{
-	"additions", "baseRefName", "changedFiles", "headRefName", "isDraft",
+	"id", "additions", "deletions", "number", "baseRefName", "changedFiles", "headRefName", "isDraft",
 	"comments", "commits", "files", "mergeStateStatus", "mergeable",
 	"statusCheckRollup", "title", "updatedAt", "url", "closed",
 }
@@ -185,7 +293,7 @@ func (g *ghClient) PRStatus(identifier string) (PRStatusResponse, error) {
 	}
 
 	if len(resp.CurrentBranch.Commits) == 0 {
-		return PRStatusResponse{}, fmt.Errorf("no pull request available")
+		return PRStatusResponse{}, ErrNoPullRequest
 	}
 	return resp, nil
 }
@@ -0,0 +1,86 @@
+// Package ghtest provides an in-memory gh.GitHubClienter for tests.
+package ghtest
+
+import "github.com/thomasgormley/dev-cli-go/internal/gh"
+
+// Call records a single method call made on the fake client.
+type Call struct {
+	Method string
+	Args   []any
+}
+
+// Client is a recording fake of gh.GitHubClienter. Every call is appended to
+// Calls and answered with the configured response.
+type Client struct {
+	AuthStatusErr error
+	CreatePRErr   error
+	ViewPRErr     error
+	MergePRErr    error
+
+	// PRStatusResponses are returned in order, one per call, repeating the
+	// last one once exhausted, so polling commands can be driven through
+	// several states.
+	PRStatusResponses []gh.PRStatusResponse
+	PRStatusErr       error
+
+	Calls []Call
+}
+
+var _ gh.GitHubClienter = (*Client)(nil)
+
+func (c *Client) AuthStatus() error {
+	c.record("AuthStatus")
+	return c.AuthStatusErr
+}
+
+func (c *Client) CreatePR(title, body, base string, draft bool) error {
+	c.record("CreatePR", title, body, base, draft)
+	return c.CreatePRErr
+}
+
+func (c *Client) ViewPR(identifier string) error {
+	c.record("ViewPR", identifier)
+	return c.ViewPRErr
+}
+
+func (c *Client) PRStatus(identifier string) (gh.PRStatusResponse, error) {
+	calls := len(c.CallsTo("PRStatus"))
+	c.record("PRStatus", identifier)
+
+	if c.PRStatusErr != nil {
+		return gh.PRStatusResponse{}, c.PRStatusErr
+	}
+	if len(c.PRStatusResponses) == 0 {
+		return gh.PRStatusResponse{}, gh.ErrNoPullRequest
+	}
+	return c.PRStatusResponses[min(calls, len(c.PRStatusResponses)-1)], nil
+}
+
+func (c *Client) MergePR(strategy gh.MergeStrategy) error {
+	c.record("MergePR", strategy)
+	return c.MergePRErr
+}
+
+// CallsTo returns the recorded calls to method, in order.
+func (c *Client) CallsTo(method string) []Call {
+	var calls []Call
+	for _, call := range c.Calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Methods returns the names of every recorded call, in order.
+func (c *Client) Methods() []string {
+	var methods []string
+	for _, call := range c.Calls {
+		methods = append(methods, call.Method)
+	}
+	return methods
+}
+
+func (c *Client) record(method string, args ...any) {
+	c.Calls = append(c.Calls, Call{Method: method, Args: args})
+}
@@ -0,0 +1,79 @@
+package gh
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNoToken = errors.New("no GitHub token found, set GH_TOKEN or run `gh auth login`")
+
+// LookupToken finds a GitHub token the same way gh does, from GH_TOKEN or
+// GITHUB_TOKEN, then from the github.com entry in gh's hosts.yml.
+func LookupToken() (string, error) {
+	for _, env := range []string{"GH_TOKEN", "GITHUB_TOKEN"} {
+		if token := os.Getenv(env); token != "" {
+			return token, nil
+		}
+	}
+
+	file, err := os.Open(ghHostsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrNoToken
+		}
+		return "", err
+	}
+	defer file.Close()
+
+	// hosts.yml is simple enough that a line scan beats pulling in a YAML
+	// parser, we only want the oauth_token directly under github.com:
+	//
+	//	github.com:
+	//	    user: thomasgormley
+	//	    oauth_token: gho_xxx
+	inHost := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") {
+			inHost = trimmed == "github.com:"
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if inHost && ok && key == "oauth_token" && indent(line) == 4 {
+			if token := strings.Trim(strings.TrimSpace(value), `"'`); token != "" {
+				return token, nil
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	// Newer gh versions keep the token in the system keyring instead
+	return "", ErrNoToken
+}
+
+func ghHostsPath() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "hosts.yml")
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh", "hosts.yml")
+	}
+	return filepath.Join(os.Getenv("HOME"), ".config", "gh", "hosts.yml")
+}
+
+func indent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
@@ -1,9 +1,43 @@
 package cli
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/thomasgormley/dev-cli-go/internal/git"
 )
 
+// gitHints suggest a fix for the git failures that can be recognised
+var gitHints = []struct {
+	err  error
+	hint string
+}{
+	{git.ErrNotARepo, "Run this from inside a git repository"},
+	{git.ErrNoUpstream, "The branch isn't on the remote yet, push it with `git push -u`"},
+	{git.ErrAuthFailed, "Check git can reach the remote, e.g. `ssh -T git@github.com` or `gh auth setup-git`"},
+	{git.ErrNonFastForward, "The remote has commits you don't, pull them with `git pull --rebase` and try again"},
+}
+
+// gitErrorMessage describes err with a hint on how to fix it, when it's a
+// git failure with a known cause.
+func gitErrorMessage(err error) string {
+	for _, h := range gitHints {
+		if !errors.Is(err, h.err) {
+			continue
+		}
+
+		// Commands run in another directory, like the diary's, need that
+		// directory fixing rather than the current one
+		hint := h.hint
+		var gitErr *git.Error
+		if h.err == git.ErrNotARepo && errors.As(err, &gitErr) && gitErr.Dir != "" {
+			hint = fmt.Sprintf("%s isn't a git repository, check the path is right", gitErr.Dir)
+		}
+		return err.Error() + "\n" + hint
+	}
+	return err.Error()
+}
+
 func isGitRepo() bool {
 	return git.IsRepo()
 }
@@ -12,6 +46,53 @@ func gitBranch() (string, error) {
 	return git.CurrentBranch()
 }
 
-func repoPRTemplate() string {
-	return git.GetPRTemplate()
+func hasUncommittedChanges() (bool, error) {
+	return git.HasUncommittedChanges()
+}
+
+func branchTracking() (git.Tracking, error) {
+	return git.TrackingStatus()
+}
+
+func pushBranch(branch string) error {
+	return git.PushUpstream("origin", branch)
+}
+
+func repoPRTemplates() ([]git.PRTemplate, error) {
+	return git.PRTemplates()
+}
+
+// baseRef resolves the ref to compare HEAD against, preferring the remote
+// branch since the local one is often stale, and falling back to the remote's
+// default branch when no base is given.
+func baseRef(base string) (string, error) {
+	if base == "" {
+		b, err := git.DefaultBranch()
+		if err != nil {
+			return "", err
+		}
+		base = b
+	}
+
+	if git.RefExists("origin/" + base) {
+		return "origin/" + base, nil
+	}
+	return base, nil
+}
+
+func commitsSince(base string) ([]git.LogEntry, error) {
+	ref, err := baseRef(base)
+	if err != nil {
+		return nil, err
+	}
+	return git.Log(ref + "..HEAD")
+}
+
+func diffStatSince(base string) (string, error) {
+	ref, err := baseRef(base)
+	if err != nil {
+		return "", err
+	}
+	// Three dots so we only diff against the merge base
+	return git.DiffStat(ref + "...HEAD")
 }
@@ -0,0 +1,66 @@
+package git
+
+// The functions below run against the repository in the current directory.
+
+var cwd = &Repo{}
+
+// IsRepo checks if the current directory is inside a git repository
+func IsRepo() bool { return cwd.IsRepo() }
+
+// CurrentBranch returns the name of the current git branch
+func CurrentBranch() (string, error) { return cwd.CurrentBranch() }
+
+// AddRemote adds a remote named name pointing at url
+func AddRemote(name, url string) error { return cwd.AddRemote(name, url) }
+
+// Root returns the root directory of the git repository
+func Root() (string, error) { return cwd.Root() }
+
+// RemoteURL returns the URL of the named remote
+func RemoteURL(remote string) (string, error) { return cwd.RemoteURL(remote) }
+
+// Add stages files or directories for commit
+func Add(paths ...string) error { return cwd.Add(paths...) }
+
+// HasUncommittedChanges checks if there are uncommitted changes in the specified paths
+func HasUncommittedChanges(paths ...string) (bool, error) { return cwd.HasUncommittedChanges(paths...) }
+
+// Status returns the porcelain status output for the specified paths
+func Status(paths ...string) (string, error) { return cwd.Status(paths...) }
+
+// TrackingStatus returns the upstream of the current branch and how many
+// commits it is ahead and behind it
+func TrackingStatus() (Tracking, error) { return cwd.TrackingStatus() }
+
+// PushUpstream pushes branch to remote and sets it as the branch's upstream
+func PushUpstream(remote, branch string) error { return cwd.PushUpstream(remote, branch) }
+
+// Commit creates a commit with the specified message
+func Commit(message string) error { return cwd.Commit(message) }
+
+// Push pushes changes to the specified remote and branch
+func Push(remote, branch string) error { return cwd.Push(remote, branch) }
+
+// Log returns the commits in revRange (e.g. main..HEAD), oldest first
+func Log(revRange string) ([]LogEntry, error) { return cwd.Log(revRange) }
+
+// DiffStat returns the `git diff --stat` summary for revRange
+func DiffStat(revRange string) (string, error) { return cwd.DiffStat(revRange) }
+
+// RefExists checks if ref resolves to a commit
+func RefExists(ref string) bool { return cwd.RefExists(ref) }
+
+// DefaultBranch returns the branch origin/HEAD points at, e.g. main
+func DefaultBranch() (string, error) { return cwd.DefaultBranch() }
+
+// Fetch updates the remote tracking branches of remote
+func Fetch(remote string) error { return cwd.Fetch(remote) }
+
+// PullRebase rebases the current branch's commits onto branch of remote
+func PullRebase(remote, branch string) error { return cwd.PullRebase(remote, branch) }
+
+// AbortRebase abandons an in-progress rebase, restoring the branch
+func AbortRebase() error { return cwd.AbortRebase() }
+
+// ConflictedFiles returns the unmerged files, relative to the repository root
+func ConflictedFiles() ([]string, error) { return cwd.ConflictedFiles() }
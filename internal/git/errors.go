@@ -0,0 +1,86 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrNotARepo       = errors.New("not a git repository")
+	ErrNoUpstream     = errors.New("no upstream branch")
+	ErrAuthFailed     = errors.New("authentication failed")
+	ErrNonFastForward = errors.New("rejected as non-fast-forward")
+)
+
+// stderrPatterns recognise the sentinel errors from git's messages, which
+// are lower cased before matching
+var stderrPatterns = []struct {
+	err      error
+	patterns []string
+}{
+	{ErrNotARepo, []string{"not a git repository"}},
+	{ErrNoUpstream, []string{"no upstream configured", "has no upstream branch", "couldn't find remote ref"}},
+	{ErrAuthFailed, []string{"authentication failed", "permission denied", "could not read username", "could not read from remote repository"}},
+	{ErrNonFastForward, []string{"non-fast-forward", "fetch first", "updates were rejected"}},
+}
+
+// Error is a failed git command, with what it printed to stderr. It matches
+// ErrNotARepo, ErrNoUpstream, ErrAuthFailed and ErrNonFastForward with
+// errors.Is when stderr says that's why it failed.
+type Error struct {
+	// Dir is where git ran, empty for the current directory
+	Dir      string
+	Args     []string
+	ExitCode int
+	Stderr   string
+	Err      error
+}
+
+func (e *Error) Error() string {
+	command := "git"
+	if len(e.Args) > 0 {
+		command += " " + e.Args[0]
+	}
+	if message := e.message(); message != "" {
+		return fmt.Sprintf("%s: %s", command, message)
+	}
+	return fmt.Sprintf("%s: %v", command, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	stderr := strings.ToLower(e.Stderr)
+	for _, p := range stderrPatterns {
+		if p.err != target {
+			continue
+		}
+		for _, pattern := range p.patterns {
+			if strings.Contains(stderr, pattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// message picks the line of stderr that explains the failure, preferring
+// git's fatal: and error: lines over hints and progress.
+func (e *Error) message() string {
+	var fallback string
+	for _, line := range strings.Split(e.Stderr, "\n") {
+		line = strings.TrimSpace(line)
+		for _, prefix := range []string{"fatal: ", "error: "} {
+			if message, ok := strings.CutPrefix(line, prefix); ok {
+				return message
+			}
+		}
+		if fallback == "" && line != "" && !strings.HasPrefix(line, "hint: ") {
+			fallback = line
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,91 @@
+package git
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestErrorFromGit(t *testing.T) {
+	err := Open(t.TempDir()).Add(".")
+
+	var gitErr *Error
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("expected a *git.Error, got %T: %v", err, err)
+	}
+	if gitErr.ExitCode != 128 || gitErr.Args[0] != "add" {
+		t.Errorf("expected git add to exit 128, got %+v", gitErr)
+	}
+	if !errors.Is(err, ErrNotARepo) {
+		t.Errorf("expected ErrNotARepo, got %v", err)
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Errorf("expected the *exec.ExitError to be wrapped")
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	tests := []struct {
+		stderr   string
+		expected error
+		message  string
+	}{
+		{
+			stderr:   "fatal: not a git repository (or any of the parent directories): .git\n",
+			expected: ErrNotARepo,
+			message:  "git push: not a git repository (or any of the parent directories): .git",
+		},
+		{
+			stderr:   "fatal: no upstream configured for branch 'feature'\n",
+			expected: ErrNoUpstream,
+			message:  "git push: no upstream configured for branch 'feature'",
+		},
+		{
+			stderr:   "git@github.com: Permission denied (publickey).\nfatal: Could not read from remote repository.\n",
+			expected: ErrAuthFailed,
+			message:  "git push: Could not read from remote repository.",
+		},
+		{
+			stderr:   "To github.com:acme/diary.git\n ! [rejected]        main -> main (fetch first)\nerror: failed to push some refs to 'github.com:acme/diary.git'\nhint: Updates were rejected because the remote contains work that you do not\n",
+			expected: ErrNonFastForward,
+			message:  "git push: failed to push some refs to 'github.com:acme/diary.git'",
+		},
+	}
+
+	sentinels := []error{ErrNotARepo, ErrNoUpstream, ErrAuthFailed, ErrNonFastForward}
+	for _, tt := range tests {
+		t.Run(tt.expected.Error(), func(t *testing.T) {
+			err := &Error{Args: []string{"push"}, ExitCode: 128, Stderr: tt.stderr}
+
+			for _, sentinel := range sentinels {
+				if got := errors.Is(err, sentinel); got != (sentinel == tt.expected) {
+					t.Errorf("errors.Is(err, %q) = %v", sentinel, got)
+				}
+			}
+			if err.Error() != tt.message {
+				t.Errorf("expected message %q, got %q", tt.message, err.Error())
+			}
+		})
+	}
+}
+
+func TestHasUncommittedChanges(t *testing.T) {
+	repo := Open(initRepo(t))
+
+	changed, err := repo.HasUncommittedChanges()
+	if err != nil || changed {
+		t.Fatalf("expected a clean repository, got %v, %v", changed, err)
+	}
+
+	run(t, repo.Dir, "sh", "-c", "echo hello > "+filepath.Join(repo.Dir, "README.md"))
+	run(t, repo.Dir, "git", "add", "README.md")
+	if changed, err := repo.HasUncommittedChanges(); err != nil || !changed {
+		t.Errorf("expected staged changes, got %v, %v", changed, err)
+	}
+
+	if _, err := Open(t.TempDir()).HasUncommittedChanges(); !errors.Is(err, ErrNotARepo) {
+		t.Errorf("expected ErrNotARepo outside a repository, got %v", err)
+	}
+}
@@ -1,27 +1,96 @@
+// Package git runs git commands against a repository. Repo runs them in its
+// directory, the free functions run them in the current directory.
 package git
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"os/exec"
+	"strconv"
+	"strings"
 )
 
-// IsRepo checks if the current directory is inside a git repository
-func IsRepo() bool {
-	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
-	return cmd.Run() == nil
+// Repo is a git repository, or a directory within one. Commands run with Dir
+// as their working directory, so a Repo is safe to use alongside others and
+// never changes the process's working directory.
+type Repo struct {
+	// Dir is the current directory when empty
+	Dir string
 }
 
-// CurrentBranch returns the name of the current git branch
-func CurrentBranch() (string, error) {
-	cmd := exec.Command("git", "branch", "--show-current")
+// Open returns the repository containing dir
+func Open(dir string) *Repo {
+	return &Repo{Dir: dir}
+}
+
+// Init creates a new repository in dir with branch as the initial branch
+func Init(dir, branch string) (*Repo, error) {
+	if err := (&Repo{}).run("init", "--quiet", "--initial-branch", branch, dir); err != nil {
+		return nil, err
+	}
+	return Open(dir), nil
+}
+
+func (r *Repo) command(args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	return cmd
+}
+
+// run runs a git command, returning an *Error with its stderr if it fails
+func (r *Repo) run(args ...string) error {
+	_, err := r.output(args...)
+	return err
+}
+
+// output runs a git command and returns its stdout, or an *Error with its
+// stderr if it fails
+func (r *Repo) output(args ...string) ([]byte, error) {
+	cmd := r.command(args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
 	out, err := cmd.Output()
+	if err != nil {
+		gitErr := &Error{Dir: r.Dir, Args: args, ExitCode: -1, Stderr: stderr.String(), Err: err}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			gitErr.ExitCode = exitErr.ExitCode()
+		}
+		return out, gitErr
+	}
+	return out, nil
+}
+
+// IsRepo checks if the directory is inside a git repository
+func (r *Repo) IsRepo() bool {
+	return r.run("rev-parse", "--is-inside-work-tree") == nil
+}
+
+// CurrentBranch returns the name of the current git branch
+func (r *Repo) CurrentBranch() (string, error) {
+	out, err := r.output("branch", "--show-current")
 	return string(bytes.TrimSpace(out)), err
 }
 
+// AddRemote adds a remote named name pointing at url
+func (r *Repo) AddRemote(name, url string) error {
+	return r.run("remote", "add", name, url)
+}
+
 // Root returns the root directory of the git repository
-func Root() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	out, err := cmd.Output()
+func (r *Repo) Root() (string, error) {
+	out, err := r.output("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSpace(out)), nil
+}
+
+// RemoteURL returns the URL of the named remote
+func (r *Repo) RemoteURL(remote string) (string, error) {
+	out, err := r.output("remote", "get-url", remote)
 	if err != nil {
 		return "", err
 	}
@@ -29,47 +98,177 @@ func Root() (string, error) {
 }
 
 // Add stages files or directories for commit
-func Add(paths ...string) error {
-	args := append([]string{"add"}, paths...)
-	return exec.Command("git", args...).Run()
+func (r *Repo) Add(paths ...string) error {
+	return r.run(append([]string{"add"}, paths...)...)
 }
 
 // HasUncommittedChanges checks if there are uncommitted changes in the specified paths
-func HasUncommittedChanges(paths ...string) (bool, error) {
-	// Check both working directory and staged changes
-	args1 := append([]string{"diff", "--quiet", "--"}, paths...)
-	args2 := append([]string{"diff", "--cached", "--quiet", "--"}, paths...)
-
-	cmd1 := exec.Command("git", args1...)
-	cmd2 := exec.Command("git", args2...)
+func (r *Repo) HasUncommittedChanges(paths ...string) (bool, error) {
+	// Check both working directory and staged changes, --quiet exits 1 when
+	// there are differences
+	for _, args := range [][]string{
+		{"diff", "--quiet", "--"},
+		{"diff", "--cached", "--quiet", "--"},
+	} {
+		err := r.run(append(args, paths...)...)
+		var gitErr *Error
+		switch {
+		case err == nil:
+			continue
+		case errors.As(err, &gitErr) && gitErr.ExitCode == 1:
+			return true, nil
+		default:
+			return false, err
+		}
+	}
+	return false, nil
+}
 
-	err1 := cmd1.Run()
-	err2 := cmd2.Run()
+// Status returns the porcelain status output for the specified paths
+func (r *Repo) Status(paths ...string) (string, error) {
+	out, err := r.output(append([]string{"status", "--porcelain"}, paths...)...)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
 
-	// If either command returns non-zero exit code, there are changes
-	return err1 != nil || err2 != nil, nil
+// Tracking describes how the current branch compares to its upstream
+type Tracking struct {
+	// Upstream is empty when the branch has no upstream, e.g. origin/main
+	Upstream string
+	Ahead    int
+	Behind   int
 }
 
-// Status returns the porcelain status output for the specified paths
-func Status(paths ...string) (string, error) {
-	args := append([]string{"status", "--porcelain"}, paths...)
-	cmd := exec.Command("git", args...)
-	var out bytes.Buffer
-	cmd.Stdout = &out
+// TrackingStatus returns the upstream of the current branch and how many
+// commits it is ahead and behind it
+func (r *Repo) TrackingStatus() (Tracking, error) {
+	out, err := r.output("rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if errors.Is(err, ErrNoUpstream) {
+		return Tracking{}, nil
+	}
+	if err != nil {
+		return Tracking{}, err
+	}
+	tracking := Tracking{Upstream: string(bytes.TrimSpace(out))}
 
-	if err := cmd.Run(); err != nil {
-		return "", err
+	out, err = r.output("rev-list", "--left-right", "--count", "@{upstream}...HEAD")
+	if err != nil {
+		return Tracking{}, err
+	}
+
+	counts := strings.Fields(string(out))
+	if len(counts) != 2 {
+		return Tracking{}, fmt.Errorf("unexpected rev-list output %q", out)
+	}
+	if tracking.Behind, err = strconv.Atoi(counts[0]); err != nil {
+		return Tracking{}, err
 	}
+	if tracking.Ahead, err = strconv.Atoi(counts[1]); err != nil {
+		return Tracking{}, err
+	}
+
+	return tracking, nil
+}
 
-	return out.String(), nil
+// PushUpstream pushes branch to remote and sets it as the branch's upstream
+func (r *Repo) PushUpstream(remote, branch string) error {
+	return r.run("push", "-u", remote, branch)
 }
 
 // Commit creates a commit with the specified message
-func Commit(message string) error {
-	return exec.Command("git", "commit", "-m", message).Run()
+func (r *Repo) Commit(message string) error {
+	return r.run("commit", "-m", message)
 }
 
 // Push pushes changes to the specified remote and branch
-func Push(remote, branch string) error {
-	return exec.Command("git", "push", remote, branch).Run()
+func (r *Repo) Push(remote, branch string) error {
+	return r.run("push", remote, branch)
+}
+
+// LogEntry is a single commit as returned by Log
+type LogEntry struct {
+	Hash    string
+	Subject string
+	Body    string
+}
+
+// Log returns the commits in revRange (e.g. main..HEAD), oldest first
+func (r *Repo) Log(revRange string) ([]LogEntry, error) {
+	// Separate fields with the unit separator and commits with the record
+	// separator so multi-line bodies survive intact
+	out, err := r.output("log", "--reverse", "--format=%H%x1f%s%x1f%b%x1e", revRange)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []LogEntry
+	for _, record := range strings.Split(string(out), "\x1e") {
+		fields := strings.SplitN(strings.TrimSpace(record), "\x1f", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		entries = append(entries, LogEntry{
+			Hash:    fields[0],
+			Subject: fields[1],
+			Body:    strings.TrimSpace(fields[2]),
+		})
+	}
+	return entries, nil
+}
+
+// DiffStat returns the `git diff --stat` summary for revRange
+func (r *Repo) DiffStat(revRange string) (string, error) {
+	out, err := r.output("diff", "--stat", revRange)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// RefExists checks if ref resolves to a commit
+func (r *Repo) RefExists(ref string) bool {
+	return r.run("rev-parse", "--verify", "--quiet", ref+"^{commit}") == nil
+}
+
+// DefaultBranch returns the branch origin/HEAD points at, e.g. main
+func (r *Repo) DefaultBranch() (string, error) {
+	out, err := r.output("symbolic-ref", "--short", "refs/remotes/origin/HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(string(bytes.TrimSpace(out)), "origin/"), nil
+}
+
+// Fetch updates the remote tracking branches of remote
+func (r *Repo) Fetch(remote string) error {
+	return r.run("fetch", "--quiet", remote)
+}
+
+// PullRebase rebases the current branch's commits onto branch of remote,
+// stashing any uncommitted changes for the duration
+func (r *Repo) PullRebase(remote, branch string) error {
+	return r.run("pull", "--rebase", "--autostash", "--quiet", remote, branch)
+}
+
+// AbortRebase abandons an in-progress rebase, restoring the branch
+func (r *Repo) AbortRebase() error {
+	return r.run("rebase", "--abort")
+}
+
+// ConflictedFiles returns the unmerged files, relative to the repository root
+func (r *Repo) ConflictedFiles() ([]string, error) {
+	out, err := r.output("diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
 }
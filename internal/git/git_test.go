@@ -0,0 +1,109 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// initRepo creates a repository with a single commit in a temp dir, cloned
+// from a bare remote, and changes into it for the duration of the test.
+func initRepo(t *testing.T) string {
+	t.Helper()
+
+	t.Setenv("GIT_AUTHOR_NAME", "dev")
+	t.Setenv("GIT_AUTHOR_EMAIL", "dev@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "dev")
+	t.Setenv("GIT_COMMITTER_EMAIL", "dev@example.com")
+
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	repo := filepath.Join(root, "repo")
+
+	run(t, root, "git", "init", "--bare", "-b", "main", remote)
+	run(t, root, "git", "clone", "-q", remote, repo)
+	run(t, repo, "git", "commit", "-q", "--allow-empty", "-m", "initial")
+	run(t, repo, "git", "push", "-q", "origin", "HEAD:main")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(repo); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	return repo
+}
+
+func run(t *testing.T, dir string, name string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%s %v failed: %v\n%s", name, args, err, out)
+	}
+}
+
+func TestTrackingStatus(t *testing.T) {
+	repo := initRepo(t)
+
+	run(t, repo, "git", "checkout", "-q", "-b", "ABC-123-feature")
+	tracking, err := TrackingStatus()
+	if err != nil {
+		t.Fatalf("TrackingStatus() returned error: %v", err)
+	}
+	if tracking != (Tracking{}) {
+		t.Errorf("expected no upstream for a new branch, got %+v", tracking)
+	}
+
+	if err := PushUpstream("origin", "ABC-123-feature"); err != nil {
+		t.Fatalf("PushUpstream() returned error: %v", err)
+	}
+	run(t, repo, "git", "commit", "-q", "--allow-empty", "-m", "one")
+	run(t, repo, "git", "commit", "-q", "--allow-empty", "-m", "two")
+
+	tracking, err = TrackingStatus()
+	if err != nil {
+		t.Fatalf("TrackingStatus() returned error: %v", err)
+	}
+	expected := Tracking{Upstream: "origin/ABC-123-feature", Ahead: 2}
+	if tracking != expected {
+		t.Errorf("expected %+v, got %+v", expected, tracking)
+	}
+}
+
+func TestRepoRunsInItsDirectory(t *testing.T) {
+	root := t.TempDir()
+	for _, branch := range []string{"main", "develop"} {
+		if _, err := Init(filepath.Join(root, branch), branch); err != nil {
+			t.Fatalf("Init() returned error: %v", err)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, branch := range []string{"main", "develop"} {
+		repo := Open(filepath.Join(root, branch))
+		if !repo.IsRepo() {
+			t.Errorf("expected %s to be a repository", repo.Dir)
+		}
+		got, err := repo.CurrentBranch()
+		if err != nil {
+			t.Fatalf("CurrentBranch() returned error: %v", err)
+		}
+		if got != branch {
+			t.Errorf("expected branch %s, got %s", branch, got)
+		}
+	}
+
+	if after, _ := os.Getwd(); after != wd {
+		t.Errorf("expected the working directory to stay %s, got %s", wd, after)
+	}
+}
@@ -3,33 +3,141 @@ package git
 import (
 	"os"
 	"path"
+	"sort"
+	"strings"
 )
 
+// Locations GitHub looks in for pull request templates, in priority order
 var pullRequestTemplatePaths = []string{
 	"./",
-	"./.github/PULL_REQUEST_TEMPLATE/",
 	"./.github/",
 	"./docs/",
 }
 
-const pullRequestTemplateFilename = "PULL_REQUEST_TEMPLATE.md"
+const (
+	pullRequestTemplateName = "pull_request_template"
+	defaultTemplateName     = "default"
+)
+
+var pullRequestTemplateExts = []string{".md", ".txt"}
 
-// GetPRTemplate returns the content of the pull request template if found
-func GetPRTemplate() string {
+// PRTemplate is a pull request template found in the repository
+type PRTemplate struct {
+	// Name is "default" for a single PULL_REQUEST_TEMPLATE.md, otherwise the
+	// file name without its extension from a PULL_REQUEST_TEMPLATE/ directory
+	Name string
+	Path string
+}
+
+// Content reads the template from disk
+func (t PRTemplate) Content() (string, error) {
+	file, err := os.ReadFile(t.Path)
+	if err != nil {
+		return "", err
+	}
+	return string(file), nil
+}
+
+// PRTemplates returns every pull request template in the current repository
+func PRTemplates() ([]PRTemplate, error) {
 	root, err := Root()
 	if err != nil {
-		return ""
+		return nil, err
 	}
+	return FindPRTemplates(root)
+}
+
+// FindPRTemplates returns the pull request templates under root, matching
+// names case-insensitively like GitHub does. The default template comes
+// first, followed by the named templates sorted by name. When there are
+// several defaults only the first is returned, in the order root,
+// .github/PULL_REQUEST_TEMPLATE/, .github/, then docs/.
+func FindPRTemplates(root string) ([]PRTemplate, error) {
+	var defaults, named []PRTemplate
 
 	for _, p := range pullRequestTemplatePaths {
-		templatePath := path.Join(root, p, pullRequestTemplateFilename)
-		if _, err := os.Stat(templatePath); err == nil {
-			file, err := os.ReadFile(templatePath)
-			if err != nil {
-				return ""
+		dir := path.Join(root, p)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		// A PULL_REQUEST_TEMPLATE.md in the templates directory is the
+		// default, ahead of the one next to the directory
+		var single []PRTemplate
+		for _, entry := range entries {
+			name := strings.ToLower(entry.Name())
+
+			if entry.IsDir() && name == pullRequestTemplateName {
+				dirDefaults, templates, err := findNamedPRTemplates(path.Join(dir, entry.Name()))
+				if err != nil {
+					return nil, err
+				}
+				defaults = append(defaults, dirDefaults...)
+				named = append(named, templates...)
+				continue
+			}
+
+			if !entry.IsDir() && isTemplateFile(name) && trimExt(name) == pullRequestTemplateName {
+				single = append(single, PRTemplate{
+					Name: defaultTemplateName,
+					Path: path.Join(dir, entry.Name()),
+				})
 			}
-			return string(file)
 		}
+		defaults = append(defaults, single...)
 	}
-	return ""
+
+	sort.SliceStable(named, func(i, j int) bool {
+		return strings.ToLower(named[i].Name) < strings.ToLower(named[j].Name)
+	})
+
+	if len(defaults) > 0 {
+		return append(defaults[:1], named...), nil
+	}
+	return named, nil
+}
+
+// findNamedPRTemplates returns the templates in a PULL_REQUEST_TEMPLATE/
+// directory, splitting out the PULL_REQUEST_TEMPLATE.md default
+func findNamedPRTemplates(dir string) (defaults, named []PRTemplate, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, entry := range entries {
+		name := strings.ToLower(entry.Name())
+		if entry.IsDir() || !isTemplateFile(name) {
+			continue
+		}
+		if trimExt(name) == pullRequestTemplateName {
+			defaults = append(defaults, PRTemplate{
+				Name: defaultTemplateName,
+				Path: path.Join(dir, entry.Name()),
+			})
+			continue
+		}
+		named = append(named, PRTemplate{
+			Name: trimExt(entry.Name()),
+			Path: path.Join(dir, entry.Name()),
+		})
+	}
+	return defaults, named, nil
+}
+
+func isTemplateFile(name string) bool {
+	for _, ext := range pullRequestTemplateExts {
+		if path.Ext(name) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func trimExt(name string) string {
+	return strings.TrimSuffix(name, path.Ext(name))
 }
@@ -0,0 +1,126 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindPRTemplates(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		expected []string
+		// path is the default template's, when checked
+		path string
+	}{
+		{
+			name:     "no templates",
+			files:    []string{"README.md"},
+			expected: nil,
+		},
+		{
+			name:     "single root template",
+			files:    []string{"PULL_REQUEST_TEMPLATE.md"},
+			expected: []string{"default"},
+		},
+		{
+			name:     "lowercase template in .github",
+			files:    []string{".github/pull_request_template.md"},
+			expected: []string{"default"},
+		},
+		{
+			name:     "txt template in docs",
+			files:    []string{"docs/Pull_Request_Template.txt"},
+			expected: []string{"default"},
+		},
+		{
+			name: "named templates are sorted after the default",
+			files: []string{
+				".github/PULL_REQUEST_TEMPLATE.md",
+				".github/PULL_REQUEST_TEMPLATE/release.md",
+				".github/PULL_REQUEST_TEMPLATE/Bug_Fix.txt",
+				".github/PULL_REQUEST_TEMPLATE/notes.json",
+			},
+			expected: []string{"default", "Bug_Fix", "release"},
+		},
+		{
+			name: "default in the template directory",
+			files: []string{
+				".github/PULL_REQUEST_TEMPLATE/PULL_REQUEST_TEMPLATE.md",
+				".github/PULL_REQUEST_TEMPLATE/bug.md",
+			},
+			expected: []string{"default", "bug"},
+		},
+		{
+			name: "only the first default by priority",
+			files: []string{
+				"docs/PULL_REQUEST_TEMPLATE.md",
+				".github/PULL_REQUEST_TEMPLATE.md",
+				".github/PULL_REQUEST_TEMPLATE/pull_request_template.md",
+				"PULL_REQUEST_TEMPLATE.md",
+			},
+			expected: []string{"default"},
+			path:     "PULL_REQUEST_TEMPLATE.md",
+		},
+		{
+			name: "template directory default before .github",
+			files: []string{
+				".github/PULL_REQUEST_TEMPLATE.md",
+				".github/PULL_REQUEST_TEMPLATE/PULL_REQUEST_TEMPLATE.md",
+			},
+			expected: []string{"default"},
+			path:     ".github/PULL_REQUEST_TEMPLATE/PULL_REQUEST_TEMPLATE.md",
+		},
+		{
+			name: "lowercase template directory",
+			files: []string{
+				".github/pull_request_template/feature.md",
+			},
+			expected: []string{"feature"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for _, f := range tt.files {
+				p := filepath.Join(root, f)
+				if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(p, []byte(f), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			templates, err := FindPRTemplates(root)
+			if err != nil {
+				t.Fatalf("FindPRTemplates() returned error: %v", err)
+			}
+
+			var names []string
+			for _, template := range templates {
+				names = append(names, template.Name)
+
+				content, err := template.Content()
+				if err != nil {
+					t.Errorf("failed to read %s: %v", template.Path, err)
+				}
+				if rel, _ := filepath.Rel(root, template.Path); content != rel {
+					t.Errorf("expected content of %s to be %q, got %q", template.Path, rel, content)
+				}
+			}
+
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("expected templates %v, got %v", tt.expected, names)
+			}
+			if tt.path != "" {
+				if rel, _ := filepath.Rel(root, templates[0].Path); rel != tt.path {
+					t.Errorf("expected the default to be %s, got %s", tt.path, rel)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/thomasgormley/dev-cli-go/internal/diary"
+	"github.com/thomasgormley/dev-cli-go/internal/git"
+)
+
+func TestGitErrorMessage(t *testing.T) {
+	notARepo := func(dir string) error {
+		return &git.Error{Dir: dir, Args: []string{"status"}, ExitCode: 128, Stderr: "fatal: not a git repository (or any of the parent directories): .git\n"}
+	}
+
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{"current directory", gitErrorMessage(notARepo("")), "Run this from inside a git repository"},
+		{"other directory", gitErrorMessage(notARepo("/notes")), "/notes isn't a git repository"},
+		{"wrapped", gitErrorMessage(fmt.Errorf("sync: %w", notARepo("/notes"))), "/notes isn't a git repository"},
+		{"diary", diaryErrorMessage(diary.New("/diary", "origin", "main"), notARepo("/diary")), "set diary.repo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(tt.message, tt.expected) {
+				t.Errorf("expected the hint %q, got %q", tt.expected, tt.message)
+			}
+		})
+	}
+}
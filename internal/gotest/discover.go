@@ -0,0 +1,432 @@
+// Package gotest finds and runs Go tests.
+package gotest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kind is the kind of function `go test` runs
+type Kind int
+
+const (
+	KindTest Kind = iota
+	KindBenchmark
+	KindFuzz
+	KindExample
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindBenchmark:
+		return "benchmark"
+	case KindFuzz:
+		return "fuzz test"
+	case KindExample:
+		return "example"
+	default:
+		return "test"
+	}
+}
+
+// Func is a test, benchmark, fuzz test or example
+type Func struct {
+	Name string
+	Kind Kind
+
+	// ImportPath and Dir are the package the function is in
+	ImportPath string
+	Dir        string
+
+	File string
+	Line int
+
+	// Subtests are the names of the subtests found statically, as passed to
+	// t.Run, with nested subtests separated by a slash
+	Subtests []string
+}
+
+// Package is the subset of `go list -json` output needed to find tests
+type Package struct {
+	ImportPath   string
+	Dir          string
+	TestGoFiles  []string
+	XTestGoFiles []string
+}
+
+// prefixes map the name prefix of each kind to the testing type its single
+// parameter must be, examples take no parameters
+var prefixes = []struct {
+	prefix string
+	kind   Kind
+	param  string
+}{
+	{"Test", KindTest, "T"},
+	{"Benchmark", KindBenchmark, "B"},
+	{"Fuzz", KindFuzz, "F"},
+	{"Example", KindExample, ""},
+}
+
+// Discover finds the test functions in the packages matching patterns, ./...
+// by default, from dir. Packages come from `go list` so build constraints are
+// respected and testdata and vendor directories are skipped.
+func Discover(ctx context.Context, dir string, patterns ...string) ([]Func, error) {
+	packages, err := List(ctx, dir, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	var funcs []Func
+	for _, pkg := range packages {
+		found, err := pkg.Funcs()
+		if err != nil {
+			return nil, err
+		}
+		funcs = append(funcs, found...)
+	}
+	return funcs, nil
+}
+
+// List returns the packages matching patterns, ./... by default, from dir.
+func List(ctx context.Context, dir string, patterns ...string) ([]Package, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	cmd := exec.CommandContext(ctx, "go", append([]string{"list", "-e", "-json=ImportPath,Dir,TestGoFiles,XTestGoFiles"}, patterns...)...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	var packages []Package
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var pkg Package
+		err := decoder.Decode(&pkg)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse go list output: %w", err)
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// Funcs parses the package's test files for test functions, in file then
+// source order.
+func (p Package) Funcs() ([]Func, error) {
+	var funcs []Func
+	fset := token.NewFileSet()
+	for _, name := range append(append([]string{}, p.TestGoFiles...), p.XTestGoFiles...) {
+		path := filepath.Join(p.Dir, name)
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+
+		// Examples don't need the testing package, so keep going without it
+		testing := testingImportName(file)
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			kind, ok := funcKind(fn, testing)
+			if !ok {
+				continue
+			}
+			found := Func{
+				Name:       fn.Name.Name,
+				Kind:       kind,
+				ImportPath: p.ImportPath,
+				Dir:        p.Dir,
+				File:       path,
+				Line:       fset.Position(fn.Pos()).Line,
+			}
+			if kind == KindTest && fn.Body != nil {
+				if names := fn.Type.Params.List[0].Names; len(names) == 1 {
+					found.Subtests = subtests(fn.Body, names[0].Name)
+				}
+			}
+			funcs = append(funcs, found)
+		}
+	}
+	return funcs, nil
+}
+
+// testingImportName returns the name the file imports the testing package
+// as, or an empty string when it doesn't.
+func testingImportName(file *ast.File) string {
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || path != "testing" {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+		return "testing"
+	}
+	return ""
+}
+
+// funcKind reports which kind of test fn is, following the rules `go test`
+// uses: no receiver or type parameters, the right signature, and a name that
+// isn't followed by a lower case letter, so Testify isn't a test.
+func funcKind(fn *ast.FuncDecl, testing string) (Kind, bool) {
+	if fn.Recv != nil || fn.Type.TypeParams != nil || fn.Name.Name == "TestMain" {
+		return 0, false
+	}
+	if fn.Type.Results != nil && len(fn.Type.Results.List) > 0 {
+		return 0, false
+	}
+
+	for _, p := range prefixes {
+		if !isTestName(fn.Name.Name, p.prefix) {
+			continue
+		}
+
+		params := fn.Type.Params.List
+		if p.param == "" {
+			return p.kind, len(params) == 0
+		}
+		if len(params) != 1 || len(params[0].Names) > 1 {
+			return 0, false
+		}
+		return p.kind, isTestingPointer(params[0].Type, testing, p.param)
+	}
+	return 0, false
+}
+
+func isTestName(name, prefix string) bool {
+	rest, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return false
+	}
+	if rest == "" {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(rest)
+	return !unicode.IsLower(r)
+}
+
+// isTestingPointer reports whether expr is *testing.<name>
+func isTestingPointer(expr ast.Expr, testing, name string) bool {
+	star, ok := expr.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == testing && sel.Sel.Name == name
+}
+
+// subtests finds the literal names of the subtests body starts with t.Run,
+// following nested t.Run calls. Table driven names come from the table the
+// loop around t.Run ranges over, e.g. the name: fields for t.Run(tt.name, ...).
+func subtests(body *ast.BlockStmt, t string) []string {
+	var names []string
+
+	// The nodes enclosing the current one, to find the loop around t.Run
+	var stack []ast.Node
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok || !isRunCall(call, t) {
+			stack = append(stack, n)
+			return true
+		}
+
+		var found []string
+		switch arg := call.Args[0].(type) {
+		case *ast.BasicLit:
+			if name, err := strconv.Unquote(arg.Value); err == nil && arg.Kind == token.STRING {
+				found = []string{name}
+			}
+		case *ast.SelectorExpr:
+			found = tableNames(body, stack, arg)
+		}
+
+		// Subtests of the subtests, when the function is a literal
+		var nested []string
+		if fn, ok := call.Args[1].(*ast.FuncLit); ok && len(fn.Type.Params.List) == 1 && len(fn.Type.Params.List[0].Names) == 1 {
+			nested = subtests(fn.Body, fn.Type.Params.List[0].Names[0].Name)
+		}
+
+		for _, name := range found {
+			names = append(names, name)
+			for _, sub := range nested {
+				names = append(names, name+"/"+sub)
+			}
+		}
+
+		// nested has already been walked
+		return false
+	})
+	return unique(names)
+}
+
+func unique(names []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// isRunCall reports whether call is t.Run(name, f)
+func isRunCall(call *ast.CallExpr, t string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Run" || len(call.Args) != 2 {
+		return false
+	}
+	recv, ok := sel.X.(*ast.Ident)
+	return ok && recv.Name == t
+}
+
+// tableNames returns the names of the table driven subtests run with
+// t.Run(tt.name, ...), the string literals given for the name field of each
+// element of the table ranged over by the loop around t.Run, which stack
+// holds.
+func tableNames(body *ast.BlockStmt, stack []ast.Node, arg *ast.SelectorExpr) []string {
+	elem, ok := arg.X.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		loop, ok := stack[i].(*ast.RangeStmt)
+		if !ok {
+			continue
+		}
+		if value, ok := loop.Value.(*ast.Ident); !ok || value.Name != elem.Name {
+			continue
+		}
+
+		table := tableLiteral(body, loop.X)
+		if table == nil {
+			return nil
+		}
+		var names []string
+		for _, elt := range table.Elts {
+			// Map tables give the element as the value, and slices of
+			// pointers may take its address
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				elt = kv.Value
+			}
+			if unary, ok := elt.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+				elt = unary.X
+			}
+			if lit, ok := elt.(*ast.CompositeLit); ok {
+				if name, ok := fieldString(lit, arg.Sel.Name); ok {
+					names = append(names, name)
+				}
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// tableLiteral returns the composite literal x is, or that was assigned to x
+// in body.
+func tableLiteral(body *ast.BlockStmt, x ast.Expr) *ast.CompositeLit {
+	switch x := x.(type) {
+	case *ast.CompositeLit:
+		return x
+	case *ast.Ident:
+		var table *ast.CompositeLit
+		ast.Inspect(body, func(n ast.Node) bool {
+			var names, values []ast.Expr
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				names, values = n.Lhs, n.Rhs
+			case *ast.ValueSpec:
+				for _, name := range n.Names {
+					names = append(names, name)
+				}
+				values = n.Values
+			}
+			for i, name := range names {
+				if ident, ok := name.(*ast.Ident); ok && ident.Name == x.Name && i < len(values) {
+					if lit, ok := values[i].(*ast.CompositeLit); ok {
+						table = lit
+					}
+				}
+			}
+			return table == nil
+		})
+		return table
+	}
+	return nil
+}
+
+// fieldString returns the string literal given for field in lit, e.g. "adds"
+// for {name: "adds"}.
+func fieldString(lit *ast.CompositeLit, field string) (string, bool) {
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok || key.Name != field {
+			continue
+		}
+		if value, ok := kv.Value.(*ast.BasicLit); ok && value.Kind == token.STRING {
+			if name, err := strconv.Unquote(value.Value); err == nil {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// SubtestName returns name as `go test` reports and matches it, with spaces
+// replaced by underscores and unprintable characters escaped.
+func SubtestName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteByte('_')
+		case !strconv.IsPrint(r):
+			quoted := strconv.QuoteRune(r)
+			b.WriteString(quoted[1 : len(quoted)-1])
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,228 @@
+package gotest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeModule writes files, keyed by path, into a new module and returns its
+// directory.
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/project\n\ngo 1.21\n"
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDiscover(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"calc/calc.go": "package calc\n\nfunc Add(a, b int) int { return a + b }\n",
+		"calc/calc_test.go": `package calc
+
+import "testing"
+
+func TestMain(m *testing.M) {}
+
+func TestAdd(t *testing.T) {}
+
+func Testify(t *testing.T) {}
+
+func Test_underscore(t *testing.T) {}
+
+func TestWrongParam(b *testing.B) {}
+
+func TestReturns(t *testing.T) error { return nil }
+
+func TestGeneric[T any](t *testing.T) {}
+
+type suite struct{}
+
+func (suite) TestMethod(t *testing.T) {}
+
+func BenchmarkAdd(b *testing.B) {}
+
+func FuzzAdd(f *testing.F) {}
+
+func helper(t *testing.T) {}
+`,
+		"calc/example_test.go": `package calc_test
+
+import "fmt"
+
+func ExampleAdd() {
+	fmt.Println(3)
+	// Output: 3
+}
+
+func ExampleWithArgs(n int) {}
+`,
+		"calc/alias_test.go": `package calc
+
+import tst "testing"
+
+func TestAliased(t *tst.T) {}
+`,
+		"calc/tagged_test.go": `//go:build integration
+
+package calc
+
+import "testing"
+
+func TestIntegration(t *testing.T) {}
+`,
+		"calc/testdata/fixture_test.go":      "package fixture\n\nimport \"testing\"\n\nfunc TestFixture(t *testing.T) {}\n",
+		"vendor/example.com/dep/dep_test.go": "package dep\n\nimport \"testing\"\n\nfunc TestVendored(t *testing.T) {}\n",
+	})
+
+	funcs, err := Discover(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Discover() returned error: %v", err)
+	}
+
+	type found struct {
+		Name string
+		Kind Kind
+		File string
+		Line int
+	}
+	var got []found
+	for _, fn := range funcs {
+		if fn.ImportPath != "example.com/project/calc" {
+			t.Errorf("expected %s to be in example.com/project/calc, got %s", fn.Name, fn.ImportPath)
+		}
+		rel, _ := filepath.Rel(dir, fn.File)
+		got = append(got, found{fn.Name, fn.Kind, rel, fn.Line})
+	}
+
+	expected := []found{
+		{"TestAliased", KindTest, "calc/alias_test.go", 5},
+		{"TestAdd", KindTest, "calc/calc_test.go", 7},
+		{"Test_underscore", KindTest, "calc/calc_test.go", 11},
+		{"BenchmarkAdd", KindBenchmark, "calc/calc_test.go", 23},
+		{"FuzzAdd", KindFuzz, "calc/calc_test.go", 25},
+		{"ExampleAdd", KindExample, "calc/example_test.go", 5},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected:\n%+v\ngot:\n%+v", expected, got)
+	}
+}
+
+func TestDiscoverWithBuildTags(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"calc/tagged_test.go": "//go:build integration\n\npackage calc\n\nimport \"testing\"\n\nfunc TestIntegration(t *testing.T) {}\n",
+	})
+	t.Setenv("GOFLAGS", "-tags=integration")
+
+	funcs, err := Discover(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Discover() returned error: %v", err)
+	}
+	if len(funcs) != 1 || funcs[0].Name != "TestIntegration" {
+		t.Errorf("expected TestIntegration with -tags=integration, got %+v", funcs)
+	}
+}
+
+func TestDiscoverSubtests(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"calc/calc_test.go": `package calc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLiteral(t *testing.T) {
+	t.Run("adds numbers", func(t *testing.T) {
+		t.Run("negative", func(t *testing.T) {})
+	})
+	t.Run("subtracts", subtract)
+	t.Run(fmt.Sprintf("case %d", 1), func(t *testing.T) {})
+}
+
+type user struct{ name string }
+
+func TestTable(t *testing.T) {
+	admin := user{name: "decoy"}
+	tests := []struct {
+		name string
+		in   int
+		user user
+	}{
+		{name: "zero", in: 0},
+		{name: "one", in: 1, user: user{name: "nested decoy"}},
+		{name: "zero", in: 2, user: admin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {})
+	}
+}
+
+func TestMapTable(t *testing.T) {
+	for _, tt := range map[string]*struct{ name string }{
+		"first": &struct{ name string }{name: "pointer"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {})
+	}
+}
+
+func TestNotATable(t *testing.T) {
+	tt := user{name: "decoy"}
+	t.Run(tt.name, func(t *testing.T) {})
+}
+
+func TestOtherReceiver(t *testing.T) {
+	var other testing.T
+	other.Run("not mine", func(*testing.T) {})
+}
+
+func subtract(t *testing.T) {}
+`,
+	})
+
+	funcs, err := Discover(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Discover() returned error: %v", err)
+	}
+
+	got := make(map[string][]string)
+	for _, fn := range funcs {
+		got[fn.Name] = fn.Subtests
+	}
+	expected := map[string][]string{
+		"TestLiteral":       {"adds numbers", "adds numbers/negative", "subtracts"},
+		"TestTable":         {"zero", "one"},
+		"TestMapTable":      {"pointer"},
+		"TestNotATable":     nil,
+		"TestOtherReceiver": nil,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestSubtestName(t *testing.T) {
+	tests := map[string]string{
+		"adds numbers":   "adds_numbers",
+		"tab\tseparated": "tab_separated",
+		"bell\a":         `bell\a`,
+		"ünïcode/ok":     "ünïcode/ok",
+	}
+	for name, expected := range tests {
+		if got := SubtestName(name); got != expected {
+			t.Errorf("SubtestName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
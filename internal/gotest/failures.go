@@ -0,0 +1,184 @@
+package gotest
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Event is a line of `go test -json` output, see `go doc test2json`
+type Event struct {
+	Action  string
+	Package string
+	Test    string `json:",omitempty"`
+	Output  string `json:",omitempty"`
+
+	// ImportPath is set instead of Package on the build-output events of
+	// Go 1.24 and later, FailedBuild on the fail event of each package
+	// that couldn't run because that build failed
+	ImportPath  string `json:",omitempty"`
+	FailedBuild string `json:",omitempty"`
+}
+
+// Results collects which packages ran and which of their tests failed from
+// the events of a `go test -json` run.
+type Results struct {
+	// Filtered is set when only some of the tests ran, e.g. with -run, so
+	// the failures of the tests that didn't run are remembered
+	Filtered bool
+
+	// packages reports whether each package that ran failed
+	packages map[string]bool
+	ran      map[string]map[string]bool
+	failed   map[string][]string
+}
+
+// Add records event
+func (r *Results) Add(event Event) {
+	if event.Package == "" || event.Action != "pass" && event.Action != "fail" && event.Action != "skip" {
+		return
+	}
+	if r.packages == nil {
+		r.packages = make(map[string]bool)
+		r.ran = make(map[string]map[string]bool)
+		r.failed = make(map[string][]string)
+	}
+
+	if event.Test == "" {
+		r.packages[event.Package] = event.Action == "fail"
+		return
+	}
+	if r.ran[event.Package] == nil {
+		r.ran[event.Package] = make(map[string]bool)
+	}
+	r.ran[event.Package][event.Test] = true
+	if event.Action == "fail" {
+		r.failed[event.Package] = append(r.failed[event.Package], event.Test)
+	}
+}
+
+// Ran reports whether test in pkg ran, whether it passed or not
+func (r *Results) Ran(pkg, test string) bool {
+	return r.ran[pkg][test]
+}
+
+// Packages returns the import paths of the packages that ran, sorted
+func (r *Results) Packages() []string {
+	var packages []string
+	for pkg := range r.packages {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+	return packages
+}
+
+// PackageFailed reports whether pkg failed, with or without failed tests
+func (r *Results) PackageFailed(pkg string) bool {
+	return r.packages[pkg] || len(r.failed[pkg]) > 0
+}
+
+// Failed returns the failed tests of pkg, leaving out the parents of failed
+// subtests, which fail because their subtests did.
+func (r *Results) Failed(pkg string) []string {
+	tests := r.failed[pkg]
+	var leaves []string
+	for _, test := range tests {
+		if !hasFailedSubtest(tests, test) {
+			leaves = append(leaves, test)
+		}
+	}
+	return leaves
+}
+
+func hasFailedSubtest(tests []string, parent string) bool {
+	for _, test := range tests {
+		if strings.HasPrefix(test, parent+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Failures remembers the failed tests of each repository, keyed by its root,
+// so `dev test --failed` only re-runs the failures of the repository it's in.
+type Failures struct {
+	Repos map[string]RepoFailures `json:"repos"`
+}
+
+// RepoFailures are the failed tests of a repository by package import path.
+// A package without tests failed outright, e.g. it didn't build, and is run
+// in full.
+type RepoFailures map[string][]string
+
+// LoadFailures reads the failures saved at path, returning none when the file
+// doesn't exist yet or isn't JSON, e.g. the plain list of test names older
+// versions saved, so it's overwritten on the next save.
+func LoadFailures(path string) (*Failures, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Failures{Repos: make(map[string]RepoFailures)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var failures Failures
+	if err := json.Unmarshal(data, &failures); err != nil || failures.Repos == nil {
+		return &Failures{Repos: make(map[string]RepoFailures)}, nil
+	}
+	return &failures, nil
+}
+
+// Save writes the failures to path
+func (f *Failures) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Record replaces the failures of the packages that ran in root with the
+// results, leaving the failures of packages that didn't run alone. When the
+// results are filtered only the failures of the tests that ran are replaced.
+func (f *Failures) Record(root string, results *Results) {
+	repo := f.Repos[root]
+	if repo == nil {
+		repo = make(RepoFailures)
+	}
+
+	for _, pkg := range results.Packages() {
+		failed := results.Failed(pkg)
+		previous, remembered := repo[pkg]
+		if results.Filtered && remembered {
+			if previous == nil {
+				// The whole package failed, e.g. to build, and still needs
+				// running in full
+				continue
+			}
+			for _, test := range previous {
+				if !results.Ran(pkg, test) && !slices.Contains(failed, test) {
+					failed = append(failed, test)
+				}
+			}
+		}
+
+		delete(repo, pkg)
+		if results.PackageFailed(pkg) || len(failed) > 0 {
+			repo[pkg] = failed
+		}
+	}
+
+	if len(repo) == 0 {
+		delete(f.Repos, root)
+		return
+	}
+	f.Repos[root] = repo
+}
@@ -0,0 +1,158 @@
+package gotest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResults(t *testing.T) {
+	events := []Event{
+		{Action: "start", Package: "example.com/project/calc"},
+		{Action: "run", Package: "example.com/project/calc", Test: "TestAdd"},
+		{Action: "fail", Package: "example.com/project/calc", Test: "TestAdd/negative"},
+		{Action: "pass", Package: "example.com/project/calc", Test: "TestAdd/positive"},
+		{Action: "fail", Package: "example.com/project/calc", Test: "TestAdd"},
+		{Action: "fail", Package: "example.com/project/calc", Test: "TestSub"},
+		{Action: "fail", Package: "example.com/project/calc"},
+		{Action: "pass", Package: "example.com/project/strings"},
+		{Action: "output", Package: "example.com/project/broken", Output: "FAIL\texample.com/project/broken [build failed]\n"},
+		{Action: "fail", Package: "example.com/project/broken"},
+	}
+
+	var results Results
+	for _, event := range events {
+		results.Add(event)
+	}
+
+	expected := []string{"example.com/project/broken", "example.com/project/calc", "example.com/project/strings"}
+	if got := results.Packages(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected packages %q, got %q", expected, got)
+	}
+	if got := results.Failed("example.com/project/calc"); !reflect.DeepEqual(got, []string{"TestAdd/negative", "TestSub"}) {
+		t.Errorf("expected the failed subtest rather than its parent, got %q", got)
+	}
+	if !results.PackageFailed("example.com/project/broken") || results.PackageFailed("example.com/project/strings") {
+		t.Errorf("expected only the broken and calc packages to fail")
+	}
+}
+
+func TestFailuresRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "failures.json")
+
+	failures, err := LoadFailures(path)
+	if err != nil {
+		t.Fatalf("LoadFailures() returned error: %v", err)
+	}
+
+	var first Results
+	for _, event := range []Event{
+		{Action: "fail", Package: "example.com/a/calc", Test: "TestAdd"},
+		{Action: "fail", Package: "example.com/a/calc"},
+		{Action: "fail", Package: "example.com/a/strings", Test: "TestTrim"},
+		{Action: "fail", Package: "example.com/a/strings"},
+	} {
+		first.Add(event)
+	}
+	failures.Record("/repos/a", &first)
+
+	var other Results
+	other.Add(Event{Action: "fail", Package: "example.com/b", Test: "TestB"})
+	other.Add(Event{Action: "fail", Package: "example.com/b"})
+	failures.Record("/repos/b", &other)
+
+	// Re-running one package only forgets that package's failures
+	var rerun Results
+	rerun.Add(Event{Action: "pass", Package: "example.com/a/calc"})
+	failures.Record("/repos/a", &rerun)
+
+	if err := failures.Save(path); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+	loaded, err := LoadFailures(path)
+	if err != nil {
+		t.Fatalf("LoadFailures() returned error: %v", err)
+	}
+
+	expected := map[string]RepoFailures{
+		"/repos/a": {"example.com/a/strings": {"TestTrim"}},
+		"/repos/b": {"example.com/b": {"TestB"}},
+	}
+	if !reflect.DeepEqual(loaded.Repos, expected) {
+		t.Errorf("expected %v, got %v", expected, loaded.Repos)
+	}
+
+	var passed Results
+	passed.Add(Event{Action: "pass", Package: "example.com/b"})
+	loaded.Record("/repos/b", &passed)
+	if _, ok := loaded.Repos["/repos/b"]; ok {
+		t.Errorf("expected a repository without failures to be forgotten, got %v", loaded.Repos)
+	}
+}
+
+func TestFailuresRecordFiltered(t *testing.T) {
+	failures := &Failures{Repos: map[string]RepoFailures{
+		"/repos/a": {
+			"example.com/a/calc":   {"TestA", "TestB/one"},
+			"example.com/a/broken": nil,
+		},
+	}}
+
+	// Only TestA ran, and passed, so TestB is still to be fixed
+	rerun := Results{Filtered: true}
+	for _, event := range []Event{
+		{Action: "pass", Package: "example.com/a/calc", Test: "TestA"},
+		{Action: "pass", Package: "example.com/a/calc"},
+		{Action: "pass", Package: "example.com/a/broken"},
+	} {
+		rerun.Add(event)
+	}
+	failures.Record("/repos/a", &rerun)
+
+	expected := RepoFailures{
+		"example.com/a/calc":   {"TestB/one"},
+		"example.com/a/broken": nil,
+	}
+	if !reflect.DeepEqual(failures.Repos["/repos/a"], expected) {
+		t.Errorf("expected %v, got %v", expected, failures.Repos["/repos/a"])
+	}
+
+	// Once TestB/one passes too, the package is forgotten
+	fixed := Results{Filtered: true}
+	fixed.Add(Event{Action: "pass", Package: "example.com/a/calc", Test: "TestB/one"})
+	fixed.Add(Event{Action: "pass", Package: "example.com/a/calc"})
+	failures.Record("/repos/a", &fixed)
+
+	if _, ok := failures.Repos["/repos/a"]["example.com/a/calc"]; ok {
+		t.Errorf("expected the fixed package to be forgotten, got %v", failures.Repos["/repos/a"])
+	}
+}
+
+func TestLoadFailuresFromPlainTextFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "failed-tests")
+	if err := os.WriteFile(path, []byte("TestAdd\nTestSub\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	failures, err := LoadFailures(path)
+	if err != nil {
+		t.Fatalf("expected the old format to be treated as empty, got %v", err)
+	}
+	if len(failures.Repos) != 0 {
+		t.Errorf("expected no failures, got %v", failures.Repos)
+	}
+
+	var results Results
+	results.Add(Event{Action: "fail", Package: "example.com/a", Test: "TestA"})
+	results.Add(Event{Action: "fail", Package: "example.com/a"})
+	failures.Record("/repos/a", &results)
+	if err := failures.Save(path); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	loaded, err := LoadFailures(path)
+	if err != nil || !reflect.DeepEqual(loaded.Repos["/repos/a"], RepoFailures{"example.com/a": {"TestA"}}) {
+		t.Errorf("expected the file to be overwritten with the new failures, got %v (%v)", loaded, err)
+	}
+}
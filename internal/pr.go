@@ -1,38 +1,64 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"io"
-	"regexp"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/thomasgormley/dev-cli-go/internal/editor"
 	"github.com/thomasgormley/dev-cli-go/internal/gh"
+	"github.com/thomasgormley/dev-cli-go/internal/prtitle"
 	"github.com/urfave/cli/v2"
 )
 
-func handlePRCreate(stdout, stderr io.Writer, ghCli gh.GitHubClienter) cli.ActionFunc {
+func handlePRCreate(stdout, stderr io.Writer, ghCli gh.GitHubClienter, titles *prtitle.Ruleset) cli.ActionFunc {
 	return func(c *cli.Context) error {
 		if !isGitRepo() {
 			return cli.Exit("Not a git repo", 1)
 		}
 
 		if err := ghCli.AuthStatus(); err != nil {
-			return cli.Exit("Not authenticated with GitHub CLI, try running `gh auth login`", 1)
+			return cli.Exit(authErrorMessage(err), 1)
 		}
 
-		title, err := titleOrPrompt(c)
-
-		if err != nil {
+		if err := ensurePushed(c, stdout); err != nil {
 			return err
 		}
 
-		body, err := bodyOrPRTemplate(c)
+		// Like git commit, edit the pull request unless everything was given
+		// up front, prompting for the title instead when there's no editor
+		edit := c.Bool("edit")
+		if !c.IsSet("edit") {
+			_, _, hasEditor := editor.Lookup()
+			edit = hasEditor && isInteractive() && !(c.IsSet("title") && c.IsSet("body"))
+		}
+
+		var title string
+		if edit {
+			title = titleOrSuggestion(c, titles)
+		} else {
+			t, err := titleOrPrompt(c, titles)
+			if err != nil {
+				return err
+			}
+			title = t
+		}
+
+		body, err := bodyOrPRTemplate(c, stderr)
 
 		if err != nil {
 			return err
 		}
 
+		if edit {
+			title, body, err = editTitleAndBody(title, body)
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+		}
+
 		base := c.String("base")
 
 		if err := ghCli.CreatePR(title, body, base, c.Bool("draft")); err != nil {
@@ -43,6 +69,17 @@ func handlePRCreate(stdout, stderr io.Writer, ghCli gh.GitHubClienter) cli.Actio
 	}
 }
 
+// authErrorMessage explains why checking authentication failed, only
+// suggesting `gh auth login` when that would fix it. ErrNoToken already
+// says how to provide a token.
+func authErrorMessage(err error) string {
+	message := fmt.Sprintf("Not authenticated with GitHub: %v", err)
+	if errors.Is(err, gh.ErrNotLoggedIn) {
+		message += "\nTry running `gh auth login`"
+	}
+	return message
+}
+
 func handlePRView(stdout, stderr io.Writer, ghCli gh.GitHubClienter) cli.ActionFunc {
 	return func(c *cli.Context) error {
 		identifier := c.Args().First()
@@ -50,18 +87,10 @@ func handlePRView(stdout, stderr io.Writer, ghCli gh.GitHubClienter) cli.ActionF
 	}
 }
 
-func bodyOrPRTemplate(c *cli.Context) (string, error) {
-	body := c.String("body")
-	if body == "" {
-		body = repoPRTemplate()
-	}
-	return body, nil
-}
-
-func titleOrPrompt(c *cli.Context) (string, error) {
+func titleOrPrompt(c *cli.Context, titles *prtitle.Ruleset) (string, error) {
 	title := c.String("title")
 	if title == "" {
-		title, err := promptForTitle()
+		title, err := promptForTitle(titles)
 		if err != nil {
 			return "", err
 		}
@@ -70,13 +99,25 @@ func titleOrPrompt(c *cli.Context) (string, error) {
 	return c.String("title"), nil
 }
 
-func promptForTitle() (string, error) {
+func titleOrSuggestion(c *cli.Context, titles *prtitle.Ruleset) string {
+	if title := c.String("title"); title != "" {
+		return title
+	}
+
+	branch, err := gitBranch()
+	if err != nil {
+		return ""
+	}
+	return prTitleFromBranch(branch, titles)
+}
+
+func promptForTitle(titles *prtitle.Ruleset) (string, error) {
 	branch, err := gitBranch()
 	if err != nil {
 		return "", err
 	}
 
-	suggestedTitle := prTitleFromBranch(branch)
+	suggestedTitle := prTitleFromBranch(branch, titles)
 
 	prompt := &survey.Input{
 		Message: "Title",
@@ -87,21 +128,33 @@ func promptForTitle() (string, error) {
 	return title, err
 }
 
-func prTitleFromBranch(branch string) string {
-	// e.g. ABC-123-some-description or anystring-ABC-123-some-description
-	// -> ABC-123: Some description
-	re := regexp.MustCompile(`^(?:[a-zA-Z0-9]+-)?([a-zA-Z]+-\d+)-([a-z0-9-]+)$`)
-	matches := re.FindStringSubmatch(branch)
+// editTitleAndBody opens the title and body in $EDITOR, the first line
+// becoming the title and the rest the body.
+func editTitleAndBody(title, body string) (string, string, error) {
+	content, err := editor.Edit("dev-pr-*.md", title+"\n\n"+body)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to edit pull request: %w", err)
+	}
+	return parseTitleAndBody(content)
+}
 
-	if len(matches) < 3 {
-		return ""
+func parseTitleAndBody(content string) (string, string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", "", errors.New("aborting pull request due to empty title and body")
 	}
 
-	t, d := strings.ToUpper(matches[1]), matches[2]
-	d = strings.ReplaceAll(d, "-", " ")
-	if t == "" {
-		return d
+	title, body, _ := strings.Cut(content, "\n")
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", "", errors.New("aborting pull request due to empty title")
 	}
 
-	return fmt.Sprintf("%s: %s", t, d)
+	return title, strings.TrimSpace(body), nil
+}
+
+// prTitleFromBranch suggests a title using the first matching title rule,
+// e.g. ABC-123-some-description -> ABC-123: some description
+func prTitleFromBranch(branch string, titles *prtitle.Ruleset) string {
+	return titles.Title(branch)
 }
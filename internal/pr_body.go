@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/thomasgormley/dev-cli-go/internal/git"
+	"github.com/urfave/cli/v2"
+)
+
+const changesHeading = "## Changes"
+
+func bodyOrPRTemplate(c *cli.Context, stderr io.Writer) (string, error) {
+	body := c.String("body")
+	if body != "" {
+		return body, nil
+	}
+
+	template, err := prTemplate(c)
+	if err != nil {
+		return "", err
+	}
+
+	base := c.String("base")
+	commits, err := commitsSince(base)
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: failed to read commits since %q: %v\n", base, err)
+		return template, nil
+	}
+
+	diffStat, err := diffStatSince(base)
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: failed to read diffstat since %q: %v\n", base, err)
+	}
+
+	changes := changesFromCommits(commits, diffStat)
+	if template == "" {
+		return changes, nil
+	}
+	return spliceSection(template, changesHeading, changes), nil
+}
+
+// prTemplate returns the content of the template named by --template, or
+// asks which one to use when the repository has several.
+func prTemplate(c *cli.Context) (string, error) {
+	name := c.String("template")
+	templates, err := repoPRTemplates()
+	if err != nil {
+		if name != "" {
+			return "", err
+		}
+		return "", nil
+	}
+
+	if name != "" {
+		var names []string
+		for _, t := range templates {
+			if strings.EqualFold(t.Name, name) {
+				return t.Content()
+			}
+			names = append(names, t.Name)
+		}
+		return "", cli.Exit(fmt.Sprintf("PR template %q not found, available templates: %s", name, strings.Join(names, ", ")), 1)
+	}
+
+	switch {
+	case len(templates) == 0:
+		return "", nil
+	case len(templates) == 1 || !isInteractive():
+		return templates[0].Content()
+	}
+
+	var options []string
+	for _, t := range templates {
+		options = append(options, t.Name)
+	}
+
+	var selected int
+	prompt := &survey.Select{
+		Message: "PR template",
+		Options: options,
+	}
+	if err := survey.AskOne(prompt, &selected); err != nil {
+		return "", err
+	}
+
+	return templates[selected].Content()
+}
+
+// changesFromCommits summarises the commits as a bulleted list of subjects,
+// followed by the diffstat and any tickets the commits close.
+func changesFromCommits(commits []git.LogEntry, diffStat string) string {
+	if len(commits) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, commit := range commits {
+		fmt.Fprintf(&b, "- %s\n", commit.Subject)
+	}
+
+	if diffStat != "" {
+		fmt.Fprintf(&b, "\n```\n%s\n```\n", diffStat)
+	}
+
+	if refs := closingRefs(commits); len(refs) > 0 {
+		b.WriteString("\n")
+		for _, ref := range refs {
+			fmt.Fprintf(&b, "Closes %s\n", ref)
+		}
+	}
+
+	return b.String()
+}
+
+// closingRefPattern matches a closing keyword, in any case, followed by an
+// upper case ticket or an issue number, so "fix: utf-8 decoding" isn't a ref
+var closingRefPattern = regexp.MustCompile(`\b(?i:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+([A-Z][A-Z0-9]+-\d+|#\d+)\b`)
+
+// closingRefs returns the unique tickets (ABC-123) and issues (#12) referenced
+// with a closing keyword in the commit messages, in the order they appear.
+func closingRefs(commits []git.LogEntry) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	for _, commit := range commits {
+		for _, match := range closingRefPattern.FindAllStringSubmatch(commit.Subject+"\n"+commit.Body, -1) {
+			ref := match[1]
+			if !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
+// spliceSection inserts content directly under heading in the template, or
+// appends the heading and content when the template doesn't have it.
+func spliceSection(template, heading, content string) string {
+	if content == "" {
+		return template
+	}
+
+	lines := strings.Split(template, "\n")
+	for i, line := range lines {
+		if strings.EqualFold(strings.TrimSpace(line), heading) {
+			spliced := append([]string{}, lines[:i+1]...)
+			spliced = append(spliced, "")
+			spliced = append(spliced, strings.Split(strings.TrimRight(content, "\n"), "\n")...)
+
+			rest := lines[i+1:]
+			if len(rest) > 0 && strings.TrimSpace(rest[0]) != "" {
+				spliced = append(spliced, "")
+			}
+			return strings.Join(append(spliced, rest...), "\n")
+		}
+	}
+
+	return strings.TrimRight(template, "\n") + "\n\n" + heading + "\n\n" + content
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thomasgormley/dev-cli-go/internal/git"
+)
+
+func TestChangesFromCommits(t *testing.T) {
+	commits := []git.LogEntry{
+		{Subject: "Add pr status command", Body: "Closes ABC-123"},
+		{Subject: "Fix watch timeout, fixes #12"},
+		{Subject: "Tidy up", Body: "resolves abc-123\nCloses ABC-456"},
+	}
+	diffStat := " internal/pr.go | 2 +-\n 1 file changed, 1 insertion(+), 1 deletion(-)"
+
+	expected := "- Add pr status command\n" +
+		"- Fix watch timeout, fixes #12\n" +
+		"- Tidy up\n" +
+		"\n```\n" + diffStat + "\n```\n" +
+		"\nCloses ABC-123\nCloses #12\nCloses ABC-456\n"
+
+	if result := changesFromCommits(commits, diffStat); result != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result)
+	}
+
+	if result := changesFromCommits(nil, diffStat); result != "" {
+		t.Errorf("expected no changes without commits, got %q", result)
+	}
+}
+
+func TestClosingRefs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"Closes ABC-123", []string{"ABC-123"}},
+		{"fixed: #7 and closes #8", []string{"#7", "#8"}},
+		{"Mentions ABC-123 without closing it", nil},
+		{"Resolve PROJ2-99", []string{"PROJ2-99"}},
+		{"FIXES abc-1 but fixes ABC-2", []string{"ABC-2"}},
+		{"fix: utf-8 decoding", nil},
+		{"fix sha-256 mismatch", nil},
+	}
+	for _, test := range tests {
+		result := closingRefs([]git.LogEntry{{Subject: test.input}})
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("For input %q, expected %v, got %v", test.input, test.expected, result)
+		}
+	}
+}
+
+func TestSpliceSection(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		content  string
+		expected string
+	}{
+		{
+			name:     "inserts under existing heading",
+			template: "## Summary\n\n## Changes\n<!-- what changed -->\n\n## Testing\n",
+			content:  "- one\n",
+			expected: "## Summary\n\n## Changes\n\n- one\n\n<!-- what changed -->\n\n## Testing\n",
+		},
+		{
+			name:     "heading match is case-insensitive",
+			template: "## changes\n\n## Testing",
+			content:  "- one\n",
+			expected: "## changes\n\n- one\n\n## Testing",
+		},
+		{
+			name:     "appends missing section",
+			template: "## Summary\n",
+			content:  "- one\n",
+			expected: "## Summary\n\n## Changes\n\n- one\n",
+		},
+		{
+			name:     "no content leaves template alone",
+			template: "## Changes\n",
+			content:  "",
+			expected: "## Changes\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := spliceSection(tt.template, changesHeading, tt.content); result != tt.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.expected, result)
+			}
+		})
+	}
+}
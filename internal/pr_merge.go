@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/thomasgormley/dev-cli-go/internal/gh"
+	"github.com/urfave/cli/v2"
+)
+
+func handlePRMerge(stdout, stderr io.Writer, ghCli gh.GitHubClienter) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		status, err := ghCli.PRStatus("")
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		if err := checkMergeable(status); err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		strategy, err := strategyOrPrompt(c)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(stdout, "Merging #%d with %s...\n", status.CurrentBranch.Number, strategy)
+		if err := ghCli.MergePR(strategy); err != nil {
+			fmt.Fprintf(stderr, "Failed to merge ❌\n")
+			return cli.Exit(err, 1)
+		}
+
+		fmt.Fprintf(stdout, "Merged ✅\n")
+		return nil
+	}
+}
+
+// checkMergeable refuses pull requests that GitHub won't merge, or that we
+// shouldn't merge without some action first, explaining what to do instead.
+func checkMergeable(status gh.PRStatusResponse) error {
+	pr := status.CurrentBranch
+	if pr.Closed {
+		return fmt.Errorf("pull request #%d is closed", pr.Number)
+	}
+
+	hints := map[gh.MergeStateStatus]string{
+		gh.BLOCKED: "get the required reviews and checks passing first, see `dev pr status`",
+		gh.DIRTY:   fmt.Sprintf("merge or rebase %s into %s and resolve the conflicts", pr.BaseRefName, pr.HeadRefName),
+		gh.DRAFT:   "mark it as ready for review with `gh pr ready`",
+		gh.BEHIND:  fmt.Sprintf("update %s with the latest %s and push", pr.HeadRefName, pr.BaseRefName),
+	}
+	if hint, ok := hints[pr.MergeStateStatus]; ok {
+		return fmt.Errorf("refusing to merge (%s): %s, %s", pr.MergeStateStatus, pr.MergeStateStatus.Description(), hint)
+	}
+
+	return nil
+}
+
+func strategyOrPrompt(c *cli.Context) (gh.MergeStrategy, error) {
+	if s := c.String("strategy"); s != "" {
+		return gh.ParseMergeStrategy(s)
+	}
+
+	var options []string
+	for _, strategy := range gh.MergeStrategies {
+		options = append(options, string(strategy))
+	}
+
+	var selected string
+	prompt := &survey.Select{
+		Message: "Merge strategy",
+		Options: options,
+	}
+	if err := survey.AskOne(prompt, &selected); err != nil {
+		return "", err
+	}
+
+	return gh.ParseMergeStrategy(selected)
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/thomasgormley/dev-cli-go/internal/gh"
+)
+
+func TestCheckMergeable(t *testing.T) {
+	tests := []struct {
+		state     gh.MergeStateStatus
+		closed    bool
+		expectErr bool
+	}{
+		{gh.CLEAN, false, false},
+		{gh.UNSTABLE, false, false},
+		{gh.HAS_HOOKS, false, false},
+		{gh.BLOCKED, false, true},
+		{gh.DIRTY, false, true},
+		{gh.DRAFT, false, true},
+		{gh.BEHIND, false, true},
+		{gh.CLEAN, true, true},
+	}
+	for _, test := range tests {
+		var status gh.PRStatusResponse
+		status.CurrentBranch.MergeStateStatus = test.state
+		status.CurrentBranch.Closed = test.closed
+
+		err := checkMergeable(status)
+		if (err != nil) != test.expectErr {
+			t.Errorf("For state %s (closed: %v), expected error: %v, got %v", test.state, test.closed, test.expectErr, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/thomasgormley/dev-cli-go/internal/git"
+	"github.com/urfave/cli/v2"
+)
+
+// ensurePushed makes sure the current branch is on the remote before creating
+// a pull request for it, offering to push it when it isn't.
+func ensurePushed(c *cli.Context, stdout io.Writer) error {
+	if c.IsSet("push") && !c.Bool("push") {
+		return nil
+	}
+
+	dirty, err := hasUncommittedChanges()
+	if err != nil {
+		return cli.Exit("Failed to check for uncommitted changes: "+gitErrorMessage(err), 1)
+	}
+	if dirty {
+		return cli.Exit("You have uncommitted changes, commit or stash them before creating a pull request", 1)
+	}
+
+	branch, err := gitBranch()
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	tracking, err := branchTracking()
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Failed to compare %s with its upstream: %s", branch, gitErrorMessage(err)), 1)
+	}
+
+	if tracking.Ahead > 0 && tracking.Behind > 0 {
+		return cli.Exit(fmt.Sprintf("%s has diverged from %s, pull or rebase before creating a pull request", branch, tracking.Upstream), 1)
+	}
+
+	reason, needsPush := pushReason(branch, tracking)
+	if !needsPush {
+		return nil
+	}
+
+	if !c.Bool("push") {
+		if !isInteractive() {
+			return cli.Exit(fmt.Sprintf("%s, push it with `git push -u origin %s` or pass --push", reason, branch), 1)
+		}
+
+		push := true
+		prompt := &survey.Confirm{
+			Message: fmt.Sprintf("%s, push it to origin?", reason),
+			Default: true,
+		}
+		if err := survey.AskOne(prompt, &push); err != nil {
+			return err
+		}
+		if !push {
+			return cli.Exit("Push the branch before creating a pull request", 1)
+		}
+	}
+
+	fmt.Fprintf(stdout, "Pushing %s to origin...\n", branch)
+	if err := pushBranch(branch); err != nil {
+		return cli.Exit(fmt.Sprintf("Failed to push %s: %s", branch, gitErrorMessage(err)), 1)
+	}
+
+	return nil
+}
+
+// pushReason explains why the branch needs pushing, if it does.
+func pushReason(branch string, tracking git.Tracking) (string, bool) {
+	switch {
+	case tracking.Upstream == "":
+		return fmt.Sprintf("%s has no upstream branch", branch), true
+	case tracking.Ahead > 0:
+		return fmt.Sprintf("%s has %d unpushed commit(s)", branch, tracking.Ahead), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/thomasgormley/dev-cli-go/internal/gh"
+	"github.com/urfave/cli/v2"
+)
+
+func handlePRStatus(stdout, stderr io.Writer, ghCli gh.GitHubClienter) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		status, err := ghCli.PRStatus("")
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		renderPRStatus(stdout, status)
+		return nil
+	}
+}
+
+var checkIcons = map[gh.CheckState]string{
+	gh.CheckPass:    "✅",
+	gh.CheckFail:    "❌",
+	gh.CheckPending: "⏳",
+	gh.CheckSkip:    "⏭️",
+}
+
+func renderPRStatus(w io.Writer, status gh.PRStatusResponse) {
+	pr := status.CurrentBranch
+
+	fmt.Fprintf(w, "#%d %s\n", pr.Number, pr.Title)
+	fmt.Fprintf(w, "%s\n", pr.URL)
+	fmt.Fprintf(w, "%s → %s\n\n", pr.HeadRefName, pr.BaseRefName)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "State\t%s\n", prState(pr.Closed, pr.IsDraft))
+	fmt.Fprintf(tw, "Merge\t%s (%s)\n", pr.MergeStateStatus, pr.MergeStateStatus.Description())
+	fmt.Fprintf(tw, "Activity\t%d commits, %d comments\n", len(pr.Commits), len(pr.Comments))
+	tw.Flush()
+
+	renderChecks(w, pr.StatusCheckRollup)
+
+	fmt.Fprintf(w, "\nFiles (%d changed, +%d -%d)\n", pr.ChangedFiles, pr.Additions, pr.Deletions)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, f := range pr.Files {
+		fmt.Fprintf(tw, "  %s\t+%d\t-%d\t%s\n", f.Path, f.Additions, f.Deletions, diffBar(f.Additions, f.Deletions))
+	}
+	tw.Flush()
+}
+
+func renderChecks(w io.Writer, checks []gh.StatusCheckRollup) {
+	counts := make(map[gh.CheckState]int)
+	for _, check := range checks {
+		counts[check.Bucket()]++
+	}
+
+	fmt.Fprintf(w, "\nChecks (%d passing, %d failing, %d pending, %d skipped)\n",
+		counts[gh.CheckPass], counts[gh.CheckFail], counts[gh.CheckPending], counts[gh.CheckSkip])
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, check := range checks {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", checkIcons[check.Bucket()], check.DisplayName(), check.Bucket(), check.URL())
+	}
+	tw.Flush()
+}
+
+func prState(closed, draft bool) string {
+	switch {
+	case closed:
+		return "🔴 Closed"
+	case draft:
+		return "📝 Draft"
+	default:
+		return "🟢 Open"
+	}
+}
+
+// diffBar renders a +/- bar scaled down to at most 20 characters.
+func diffBar(additions, deletions int) string {
+	const width = 20
+	total := additions + deletions
+	if total > width {
+		additions = additions * width / total
+		deletions = width - additions
+	}
+	return strings.Repeat("+", additions) + strings.Repeat("-", deletions)
+}
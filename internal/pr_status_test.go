@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/thomasgormley/dev-cli-go/internal/gh"
+)
+
+func TestRenderPRStatus(t *testing.T) {
+	input := `{"currentBranch": {
+		"number": 42,
+		"title": "ABC-123: some description",
+		"url": "https://github.com/o/r/pull/42",
+		"headRefName": "ABC-123-some-description",
+		"baseRefName": "main",
+		"isDraft": true,
+		"mergeStateStatus": "BLOCKED",
+		"additions": 12,
+		"deletions": 3,
+		"changedFiles": 1,
+		"files": [{"path": "internal/pr.go", "additions": 12, "deletions": 3}],
+		"statusCheckRollup": [
+			{"name": "build", "status": "COMPLETED", "conclusion": "SUCCESS"},
+			{"name": "lint", "status": "COMPLETED", "conclusion": "FAILURE"},
+			{"context": "ci/deploy", "state": "PENDING"}
+		]
+	}}`
+
+	var status gh.PRStatusResponse
+	if err := json.Unmarshal([]byte(input), &status); err != nil {
+		t.Fatalf("failed to unmarshal fixture: %v", err)
+	}
+
+	var out bytes.Buffer
+	renderPRStatus(&out, status)
+
+	expected := []string{
+		"#42 ABC-123: some description",
+		"ABC-123-some-description → main",
+		"📝 Draft",
+		"BLOCKED (Merging is blocked",
+		"Checks (1 passing, 1 failing, 1 pending, 0 skipped)",
+		"ci/deploy",
+		"Files (1 changed, +12 -3)",
+		"internal/pr.go",
+	}
+	for _, e := range expected {
+		if !strings.Contains(out.String(), e) {
+			t.Errorf("expected output to contain %q, got:\n%s", e, out.String())
+		}
+	}
+}
+
+func TestCheckBucket(t *testing.T) {
+	tests := []struct {
+		check    gh.StatusCheckRollup
+		expected gh.CheckState
+	}{
+		{gh.StatusCheckRollup{Status: "COMPLETED", Conclusion: "SUCCESS"}, gh.CheckPass},
+		{gh.StatusCheckRollup{Status: "COMPLETED", Conclusion: "CANCELLED"}, gh.CheckFail},
+		{gh.StatusCheckRollup{Status: "COMPLETED", Conclusion: "SKIPPED"}, gh.CheckSkip},
+		{gh.StatusCheckRollup{Status: "IN_PROGRESS"}, gh.CheckPending},
+		{gh.StatusCheckRollup{State: "ERROR"}, gh.CheckFail},
+		{gh.StatusCheckRollup{State: "PENDING"}, gh.CheckPending},
+	}
+	for _, test := range tests {
+		if result := test.check.Bucket(); result != test.expected {
+			t.Errorf("For %+v, expected %q, got %q", test.check, test.expected, result)
+		}
+	}
+}
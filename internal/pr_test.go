@@ -1,6 +1,14 @@
 package cli
 
-import "testing"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/thomasgormley/dev-cli-go/internal/gh"
+	"github.com/thomasgormley/dev-cli-go/internal/prtitle"
+)
 
 func TestPrTitleFromBranch(t *testing.T) {
 	tests := []struct {
@@ -14,10 +22,60 @@ func TestPrTitleFromBranch(t *testing.T) {
 		{"ABC-123-some", "ABC-123: some"},
 		{"abc-123-some", "ABC-123: some"},
 	}
+	titles := prtitle.MustCompile(prtitle.DefaultRules)
 	for _, test := range tests {
-		result := prTitleFromBranch(test.input)
+		result := prTitleFromBranch(test.input, titles)
 		if result != test.expected {
 			t.Errorf("For input %q, expected %q, got %q", test.input, test.expected, result)
 		}
 	}
 }
+
+func TestParseTitleAndBody(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedTitle string
+		expectedBody  string
+		expectErr     bool
+	}{
+		{"ABC-123: title\n\n## Changes\n\n- one\n", "ABC-123: title", "## Changes\n\n- one", false},
+		{"\n\n  title only  \n", "title only", "", false},
+		{"title\nbody straight after", "title", "body straight after", false},
+		{"", "", "", true},
+		{" \n\t\n", "", "", true},
+	}
+	for _, test := range tests {
+		title, body, err := parseTitleAndBody(test.input)
+		if (err != nil) != test.expectErr {
+			t.Errorf("For input %q, expected error: %v, got %v", test.input, test.expectErr, err)
+			continue
+		}
+		if title != test.expectedTitle || body != test.expectedBody {
+			t.Errorf("For input %q, expected %q and %q, got %q and %q", test.input, test.expectedTitle, test.expectedBody, title, body)
+		}
+	}
+}
+
+func TestAuthErrorMessage(t *testing.T) {
+	tests := []struct {
+		err      error
+		contains []string
+		excludes string
+	}{
+		{fmt.Errorf("%w: exit status 1", gh.ErrNotLoggedIn), []string{"exit status 1", "gh auth login"}, ""},
+		{gh.ErrNoToken, []string{"set GH_TOKEN"}, "Try running"},
+		{&gh.APIError{StatusCode: 401, Message: "Bad credentials"}, []string{"401 Bad credentials"}, "gh auth login"},
+		{errors.New("dial tcp: connection refused"), []string{"connection refused"}, "gh auth login"},
+	}
+	for _, tt := range tests {
+		message := authErrorMessage(tt.err)
+		for _, s := range tt.contains {
+			if !strings.Contains(message, s) {
+				t.Errorf("expected %q to contain %q", message, s)
+			}
+		}
+		if tt.excludes != "" && strings.Contains(message, tt.excludes) {
+			t.Errorf("expected %q not to contain %q", message, tt.excludes)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/thomasgormley/dev-cli-go/internal/prtitle"
+	"github.com/urfave/cli/v2"
+)
+
+func handlePRTitle(stdout, stderr io.Writer, titles *prtitle.Ruleset) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		branch := c.Args().First()
+		if branch == "" {
+			b, err := gitBranch()
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+			branch = b
+		}
+
+		if !c.Bool("explain") {
+			title := titles.Title(branch)
+			if title == "" {
+				return cli.Exit(fmt.Sprintf("No title rule matched %q", branch), 1)
+			}
+			fmt.Fprintln(stdout, title)
+			return nil
+		}
+
+		renderTitleExplanation(stdout, branch, titles.Explain(branch))
+		return nil
+	}
+}
+
+func renderTitleExplanation(w io.Writer, branch string, attempts []prtitle.Attempt) {
+	fmt.Fprintf(w, "Branch: %s\n\n", branch)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, attempt := range attempts {
+		icon := "❌"
+		if attempt.Matched {
+			icon = "✅"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", icon, attempt.Rule.Name, attempt.Rule.Pattern)
+	}
+	tw.Flush()
+
+	if len(attempts) == 0 || !attempts[len(attempts)-1].Matched {
+		fmt.Fprintf(w, "\nNo title rule matched\n")
+		return
+	}
+
+	matched := attempts[len(attempts)-1]
+	var captures []string
+	for name, value := range matched.Captures {
+		captures = append(captures, fmt.Sprintf("%s=%q", name, value))
+	}
+	sort.Strings(captures)
+
+	fmt.Fprintf(w, "\nCaptures: %s\n", strings.Join(captures, " "))
+	fmt.Fprintf(w, "Template: %s\n", matched.Rule.Template)
+	fmt.Fprintf(w, "Title:    %s\n", matched.Title)
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thomasgormley/dev-cli-go/internal/gh"
+	"github.com/urfave/cli/v2"
+)
+
+func handlePRWatch(stdout, stderr io.Writer, ghCli gh.GitHubClienter) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if interval := c.Duration("interval"); interval <= 0 {
+			return cli.Exit(fmt.Sprintf("Invalid --interval %s, it must be positive", interval), 1)
+		}
+
+		var strategy gh.MergeStrategy
+		if c.Bool("merge") {
+			// Ask up front so we don't block on a prompt once the checks finish
+			s, err := strategyOrPrompt(c)
+			if err != nil {
+				return err
+			}
+			strategy = s
+		}
+
+		ctx := c.Context
+		if timeout := c.Duration("timeout"); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		status, err := watchChecks(ctx, stdout, ghCli, c.Duration("interval"), c.Bool("merge"))
+		if errors.Is(err, context.DeadlineExceeded) {
+			return cli.Exit(fmt.Sprintf("Timed out after %s waiting for checks", c.Duration("timeout")), 1)
+		}
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		for _, check := range status.CurrentBranch.StatusCheckRollup {
+			if check.Bucket() == gh.CheckFail {
+				return cli.Exit("Checks failed ❌", 1)
+			}
+		}
+		fmt.Fprintf(stdout, "Checks passed ✅\n")
+
+		if strategy == "" {
+			return nil
+		}
+
+		if status.CurrentBranch.MergeStateStatus != gh.CLEAN {
+			state := status.CurrentBranch.MergeStateStatus
+			return cli.Exit(fmt.Sprintf("Not merging (%s): %s", state, state.Description()), 1)
+		}
+
+		fmt.Fprintf(stdout, "Merging #%d with %s...\n", status.CurrentBranch.Number, strategy)
+		if err := ghCli.MergePR(strategy); err != nil {
+			fmt.Fprintf(stderr, "Failed to merge ❌\n")
+			return cli.Exit(err, 1)
+		}
+
+		fmt.Fprintf(stdout, "Merged ✅\n")
+		return nil
+	}
+}
+
+// watchChecks polls the pull request status, redrawing the checks on every
+// tick, until none of the checks are pending. When waitForMergeState is set it
+// also keeps polling while GitHub is still computing the merge state. When w
+// isn't a terminal only the last status is written, as it can't be redrawn.
+func watchChecks(ctx context.Context, w io.Writer, ghCli gh.GitHubClienter, interval time.Duration, waitForMergeState bool) (gh.PRStatusResponse, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	redraw := isTerminal(w)
+	var drawn int
+	for {
+		status, err := ghCli.PRStatus("")
+		if err != nil {
+			return gh.PRStatusResponse{}, err
+		}
+
+		settled := checksSettled(status, waitForMergeState)
+		if redraw {
+			var frame bytes.Buffer
+			renderWatchFrame(&frame, status, time.Now())
+
+			// Move back up over the previous frame and clear it before redrawing
+			if drawn > 0 {
+				fmt.Fprintf(w, "\033[%dA\033[J", drawn)
+			}
+			w.Write(frame.Bytes())
+			drawn = bytes.Count(frame.Bytes(), []byte{'\n'})
+		} else if settled {
+			renderWatchFrame(w, status, time.Now())
+		}
+
+		if settled {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if !redraw {
+				renderWatchFrame(w, status, time.Now())
+			}
+			return status, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func checksSettled(status gh.PRStatusResponse, waitForMergeState bool) bool {
+	for _, check := range status.CurrentBranch.StatusCheckRollup {
+		if check.Bucket() == gh.CheckPending {
+			return false
+		}
+	}
+
+	return !waitForMergeState || status.CurrentBranch.MergeStateStatus != gh.UNKNOWN
+}
+
+func renderWatchFrame(w io.Writer, status gh.PRStatusResponse, now time.Time) {
+	pr := status.CurrentBranch
+	fmt.Fprintf(w, "#%d %s (%s)\n", pr.Number, pr.Title, pr.MergeStateStatus)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, check := range pr.StatusCheckRollup {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", checkIcons[check.Bucket()], check.DisplayName(), checkProgress(check), checkElapsed(check, now))
+	}
+	tw.Flush()
+}
+
+// checkProgress describes where a check is at, e.g. queued, in progress,
+// or its conclusion once completed.
+func checkProgress(check gh.StatusCheckRollup) string {
+	switch {
+	case check.State != "":
+		return strings.ToLower(check.State)
+	case check.Conclusion != "":
+		return strings.ToLower(check.Conclusion)
+	case check.Status != "":
+		return strings.ToLower(strings.ReplaceAll(check.Status, "_", " "))
+	default:
+		return "queued"
+	}
+}
+
+func checkElapsed(check gh.StatusCheckRollup, now time.Time) string {
+	if check.StartedAt.IsZero() {
+		return ""
+	}
+
+	end := now
+	if !check.CompletedAt.IsZero() {
+		end = check.CompletedAt
+	}
+	return end.Sub(check.StartedAt).Round(time.Second).String()
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thomasgormley/dev-cli-go/internal/gh"
+	"github.com/thomasgormley/dev-cli-go/internal/gh/ghtest"
+)
+
+func TestWatchChecksWithoutTerminal(t *testing.T) {
+	client := &ghtest.Client{PRStatusResponses: []gh.PRStatusResponse{
+		prStatus(gh.UNSTABLE, runningCheck),
+		prStatus(gh.UNSTABLE, runningCheck),
+		prStatus(gh.CLEAN, passingCheck),
+	}}
+
+	var out bytes.Buffer
+	if _, err := watchChecks(context.Background(), &out, client, time.Millisecond, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(out.String(), "\033[") {
+		t.Errorf("expected no escape codes when not writing to a terminal, got %q", out.String())
+	}
+	if n := strings.Count(out.String(), "#42 "); n != 1 {
+		t.Errorf("expected only the final frame, got %d frames:\n%s", n, out.String())
+	}
+	if !strings.Contains(out.String(), "success") {
+		t.Errorf("expected the final frame to show the passing check, got:\n%s", out.String())
+	}
+}
+
+func TestChecksSettled(t *testing.T) {
+	pass := gh.StatusCheckRollup{Status: "COMPLETED", Conclusion: "SUCCESS"}
+	fail := gh.StatusCheckRollup{Status: "COMPLETED", Conclusion: "FAILURE"}
+	running := gh.StatusCheckRollup{Status: "IN_PROGRESS"}
+
+	tests := []struct {
+		name              string
+		checks            []gh.StatusCheckRollup
+		state             gh.MergeStateStatus
+		waitForMergeState bool
+		expected          bool
+	}{
+		{"no checks", nil, gh.CLEAN, false, true},
+		{"all passed", []gh.StatusCheckRollup{pass, pass}, gh.CLEAN, false, true},
+		{"failure settles", []gh.StatusCheckRollup{pass, fail}, gh.UNSTABLE, false, true},
+		{"still running", []gh.StatusCheckRollup{pass, running}, gh.UNSTABLE, false, false},
+		{"merge state unknown", []gh.StatusCheckRollup{pass}, gh.UNKNOWN, true, false},
+		{"merge state ignored", []gh.StatusCheckRollup{pass}, gh.UNKNOWN, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var status gh.PRStatusResponse
+			status.CurrentBranch.StatusCheckRollup = tt.checks
+			status.CurrentBranch.MergeStateStatus = tt.state
+
+			if result := checksSettled(status, tt.waitForMergeState); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestCheckElapsed(t *testing.T) {
+	start := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	now := start.Add(90 * time.Second)
+
+	tests := []struct {
+		check    gh.StatusCheckRollup
+		expected string
+	}{
+		{gh.StatusCheckRollup{Status: "QUEUED"}, ""},
+		{gh.StatusCheckRollup{Status: "IN_PROGRESS", StartedAt: start}, "1m30s"},
+		{gh.StatusCheckRollup{Status: "COMPLETED", StartedAt: start, CompletedAt: start.Add(42 * time.Second)}, "42s"},
+	}
+	for _, test := range tests {
+		if result := checkElapsed(test.check, now); result != test.expected {
+			t.Errorf("For %+v, expected %q, got %q", test.check, test.expected, result)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Package prtitle derives pull request titles from branch names using an
+// ordered set of rules, the first matching rule wins.
+package prtitle
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Rule turns a branch matching Pattern into a title by filling the named
+// captures into Template.
+//
+// Template placeholders are capture names in braces, e.g. {ticket}, with
+// optional modifiers, e.g. {description|words|sentence}. The case of the name
+// is a shorthand too: {Description} is sentence cased and {TICKET} upper cased.
+type Rule struct {
+	Name     string `toml:"name"`
+	Pattern  string `toml:"pattern"`
+	Template string `toml:"template"`
+}
+
+// DefaultRules are used when no rules are configured
+var DefaultRules = []Rule{
+	{
+		// ABC-123-some-description or anystring-ABC-123-some-description
+		Name:     "ticket-prefix",
+		Pattern:  `^(?:[a-zA-Z0-9]+-)?(?P<ticket>[a-zA-Z]+-\d+)-(?P<description>[a-z0-9-]+)$`,
+		Template: "{TICKET}: {description|words}",
+	},
+	{
+		// feat/ABC-123/some-description or user/abc-123_some_description
+		Name:     "ticket-path",
+		Pattern:  `^[\w.-]+/(?P<ticket>[a-zA-Z]+-\d+)[/_-](?P<description>[\w-]+)$`,
+		Template: "{TICKET}: {description|words}",
+	},
+	{
+		// feat/some-description -> feat: some description
+		Name:     "conventional",
+		Pattern:  `^(?P<type>build|chore|ci|docs|feat|fix|perf|refactor|revert|style|test)/(?P<description>[\w-]+)$`,
+		Template: "{type}: {description|words}",
+	},
+}
+
+var modifiers = map[string]func(string) string{
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"sentence": sentenceCase,
+	"title":    titleCase,
+	"words":    words,
+}
+
+var placeholderPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+type compiledRule struct {
+	Rule
+	re *regexp.Regexp
+}
+
+// Ruleset is a compiled, ordered list of rules
+type Ruleset struct {
+	rules []compiledRule
+}
+
+// Attempt records how a single rule fared against a branch
+type Attempt struct {
+	Rule     Rule
+	Matched  bool
+	Captures map[string]string
+	Title    string
+}
+
+// Compile validates the rules, checking every pattern compiles and every
+// placeholder refers to a capture and known modifiers.
+func Compile(rules []Rule) (*Ruleset, error) {
+	ruleset := &Ruleset{}
+	for i, rule := range rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+			rule.Name = name
+		}
+
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: invalid pattern: %w", name, err)
+		}
+
+		captures := make(map[string]bool)
+		for _, capture := range re.SubexpNames() {
+			if capture != "" {
+				captures[strings.ToLower(capture)] = true
+			}
+		}
+
+		for _, match := range placeholderPattern.FindAllStringSubmatch(rule.Template, -1) {
+			parts := strings.Split(match[1], "|")
+			if !captures[strings.ToLower(parts[0])] {
+				return nil, fmt.Errorf("rule %s: template refers to unknown capture %q", name, parts[0])
+			}
+			for _, modifier := range parts[1:] {
+				if _, ok := modifiers[modifier]; !ok {
+					return nil, fmt.Errorf("rule %s: unknown modifier %q in %s", name, modifier, match[0])
+				}
+			}
+		}
+
+		ruleset.rules = append(ruleset.rules, compiledRule{Rule: rule, re: re})
+	}
+	return ruleset, nil
+}
+
+// MustCompile is like Compile but panics if the rules are invalid
+func MustCompile(rules []Rule) *Ruleset {
+	ruleset, err := Compile(rules)
+	if err != nil {
+		panic(err)
+	}
+	return ruleset
+}
+
+// Title returns the title from the first rule matching branch, or an empty
+// string when none match.
+func (r *Ruleset) Title(branch string) string {
+	attempts := r.Explain(branch)
+	if len(attempts) == 0 || !attempts[len(attempts)-1].Matched {
+		return ""
+	}
+	return attempts[len(attempts)-1].Title
+}
+
+// Explain tries each rule against branch in order, stopping at the first
+// match, and returns every attempt made.
+func (r *Ruleset) Explain(branch string) []Attempt {
+	var attempts []Attempt
+	for _, rule := range r.rules {
+		attempt := Attempt{Rule: rule.Rule}
+
+		matches := rule.re.FindStringSubmatch(branch)
+		if matches != nil {
+			attempt.Matched = true
+			attempt.Captures = make(map[string]string)
+			for i, name := range rule.re.SubexpNames() {
+				if name != "" {
+					attempt.Captures[strings.ToLower(name)] = matches[i]
+				}
+			}
+			attempt.Title = render(rule.Template, attempt.Captures)
+		}
+
+		attempts = append(attempts, attempt)
+		if attempt.Matched {
+			break
+		}
+	}
+	return attempts
+}
+
+func render(template string, captures map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		parts := strings.Split(strings.Trim(placeholder, "{}"), "|")
+		name := parts[0]
+		value := captures[strings.ToLower(name)]
+
+		switch {
+		case name == strings.ToUpper(name) && name != strings.ToLower(name):
+			value = strings.ToUpper(value)
+		case unicode.IsUpper([]rune(name)[0]):
+			parts = append(parts, "sentence")
+		}
+
+		for _, modifier := range parts[1:] {
+			value = modifiers[modifier](value)
+		}
+		return value
+	})
+}
+
+// words turns separators into spaces, e.g. some-description -> some description
+func words(s string) string {
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return r == '-' || r == '_' || r == '/'
+	}), " ")
+}
+
+func sentenceCase(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	return string(unicode.ToUpper(r[0])) + string(r[1:])
+}
+
+func titleCase(s string) string {
+	fields := strings.Fields(s)
+	for i, field := range fields {
+		fields[i] = sentenceCase(field)
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,89 @@
+package prtitle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultRules(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"ABC-123-some-description", "ABC-123: some description"},
+		{"prefix-ABC-123-some-description", "ABC-123: some description"},
+		{"feat/ABC-123/some-description", "ABC-123: some description"},
+		{"user/abc-123_some_description", "ABC-123: some description"},
+		{"feat/add-pr-title-rules", "feat: add pr title rules"},
+		{"fix/typo", "fix: typo"},
+		{"ABC-123", ""},
+		{"invalid-branch", ""},
+		{"main", ""},
+	}
+
+	titles := MustCompile(DefaultRules)
+	for _, test := range tests {
+		if result := titles.Title(test.input); result != test.expected {
+			t.Errorf("For input %q, expected %q, got %q", test.input, test.expected, result)
+		}
+	}
+}
+
+func TestTemplateModifiers(t *testing.T) {
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"{ticket}: {desc}", "abc-123: some-description"},
+		{"{TICKET}: {Desc|words}", "ABC-123: Some description"},
+		{"{ticket|upper} {desc|words|title}", "ABC-123 Some Description"},
+		{"[{ticket|upper}] {desc|words|sentence}", "[ABC-123] Some description"},
+		{"{DESC|words|lower}", "some description"},
+	}
+
+	for _, test := range tests {
+		titles := MustCompile([]Rule{{
+			Name:     "test",
+			Pattern:  `^(?P<ticket>[a-z]+-\d+)-(?P<desc>.+)$`,
+			Template: test.template,
+		}})
+		if result := titles.Title("abc-123-some-description"); result != test.expected {
+			t.Errorf("For template %q, expected %q, got %q", test.template, test.expected, result)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     Rule
+		expected string
+	}{
+		{"invalid pattern", Rule{Name: "bad", Pattern: `(`}, "rule bad: invalid pattern"},
+		{"unknown capture", Rule{Pattern: `(?P<ticket>.+)`, Template: "{scope}"}, `rule #1: template refers to unknown capture "scope"`},
+		{"unknown modifier", Rule{Name: "r", Pattern: `(?P<ticket>.+)`, Template: "{ticket|shout}"}, `rule r: unknown modifier "shout"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile([]Rule{tt.rule})
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	attempts := MustCompile(DefaultRules).Explain("feat/ABC-123/some-description")
+
+	if len(attempts) != 2 {
+		t.Fatalf("expected to stop after the second rule, got %d attempts", len(attempts))
+	}
+	if attempts[0].Matched || !attempts[1].Matched {
+		t.Errorf("expected only ticket-path to match, got %+v", attempts)
+	}
+	if attempts[1].Captures["ticket"] != "ABC-123" || attempts[1].Captures["description"] != "some-description" {
+		t.Errorf("unexpected captures %v", attempts[1].Captures)
+	}
+}
@@ -1,9 +1,14 @@
 package cli
 
 import (
+	"fmt"
 	"io"
+	"time"
 
+	"github.com/thomasgormley/dev-cli-go/internal/config"
+	"github.com/thomasgormley/dev-cli-go/internal/diary"
 	"github.com/thomasgormley/dev-cli-go/internal/gh"
+	"github.com/thomasgormley/dev-cli-go/internal/prtitle"
 	"github.com/urfave/cli/v2"
 )
 
@@ -14,8 +19,19 @@ func Run(
 	stdout,
 	stderr io.Writer,
 	ghClient gh.GitHubClienter,
+	cfg *config.Config,
+	cfgErr error,
 	exitErrorHandler cli.ExitErrHandlerFunc,
 ) error {
+	titles, err := prtitle.Compile(cfg.TitleRules())
+	if err != nil {
+		return &config.ValidationError{Key: "pr.title_rules", Message: err.Error()}
+	}
+
+	diaryRepo := diary.New(diary.RepoPath(config.ExpandPath(cfg.Diary.Repo)), cfg.Diary.Remote, cfg.Diary.Branch)
+	for _, repo := range cfg.Diary.Repos {
+		diaryRepo.Repos = append(diaryRepo.Repos, config.ExpandPath(repo))
+	}
 
 	app := &cli.App{
 		Name:                 "dev",
@@ -23,6 +39,18 @@ func Run(
 		Usage:                "Personal development CLI toolbox",
 		ExitErrHandler:       exitErrorHandler,
 		EnableBashCompletion: true,
+		// A config that doesn't load, leaving cfg with the defaults, only
+		// stops the commands that use it, so `dev config` can fix it
+		Before: func(c *cli.Context) error {
+			if cfgErr == nil {
+				return nil
+			}
+			if c.Args().First() == "config" {
+				fmt.Fprintf(stderr, "Warning: %v\n", cfgErr)
+				return nil
+			}
+			return cfgErr
+		},
 		Commands: []*cli.Command{
 			// PR definition
 			{
@@ -32,7 +60,7 @@ func Run(
 					{
 						Name:   "create",
 						Usage:  "Create a new pull request",
-						Action: handlePRCreate(stdout, stderr, ghClient),
+						Action: handlePRCreate(stdout, stderr, ghClient, titles),
 						Flags: []cli.Flag{
 							&cli.StringFlag{
 								Name:    "title",
@@ -41,14 +69,20 @@ func Run(
 							},
 							&cli.StringFlag{
 								Name:    "body",
-								Usage:   "body of the pull request",
+								Usage:   "body of the pull request, generated from the PR template and commits since base when empty",
 								Aliases: []string{"b"},
 							},
+							&cli.StringFlag{
+								Name:    "template",
+								Usage:   "name of the PR template to use when the repo has several",
+								Aliases: []string{"T"},
+							},
 							&cli.StringFlag{
 								Name:    "base",
 								Usage:   "base branch",
 								Aliases: []string{"B"},
 								EnvVars: []string{"TEAM_BRANCH"},
+								Value:   cfg.PR.Base,
 							},
 							&cli.BoolFlag{
 								Name:    "draft",
@@ -56,6 +90,27 @@ func Run(
 								Aliases: []string{"d"},
 								Value:   true,
 							},
+							&cli.BoolFlag{
+								Name:  "push",
+								Usage: "push the branch without asking when it isn't on the remote, --push=false skips the check",
+							},
+							&cli.BoolFlag{
+								Name:    "edit",
+								Usage:   "edit the title and body in $EDITOR before submitting, the default when interactive and $EDITOR is set",
+								Aliases: []string{"e"},
+							},
+						},
+					},
+					{
+						Name:      "title",
+						Usage:     "Show the title suggested for a branch, defaults to the current branch",
+						ArgsUsage: "[branch]",
+						Action:    handlePRTitle(stdout, stderr, titles),
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "explain",
+								Usage: "show which title rule matched and what it captured",
+							},
 						},
 					},
 					{
@@ -64,6 +119,54 @@ func Run(
 						Aliases: []string{"v"},
 						Action:  handlePRView(stdout, stderr, ghClient),
 					},
+					{
+						Name:    "status",
+						Usage:   "Show a dashboard for the current branch's pull request",
+						Aliases: []string{"s"},
+						Action:  handlePRStatus(stdout, stderr, ghClient),
+					},
+					{
+						Name:    "watch",
+						Usage:   "Wait for the current branch's pull request checks to finish",
+						Aliases: []string{"w"},
+						Action:  handlePRWatch(stdout, stderr, ghClient),
+						Flags: []cli.Flag{
+							&cli.DurationFlag{
+								Name:    "interval",
+								Usage:   "how often to poll the checks",
+								Aliases: []string{"i"},
+								Value:   10 * time.Second,
+							},
+							&cli.DurationFlag{
+								Name:  "timeout",
+								Usage: "give up waiting after this long, 0 waits forever",
+								Value: 30 * time.Minute,
+							},
+							&cli.BoolFlag{
+								Name:    "merge",
+								Usage:   "merge the pull request once the checks pass",
+								Aliases: []string{"m"},
+							},
+							&cli.StringFlag{
+								Name:    "strategy",
+								Usage:   "merge strategy used with --merge, one of squash, merge or rebase",
+								Aliases: []string{"s"},
+							},
+						},
+					},
+					{
+						Name:    "merge",
+						Usage:   "Merge the current branch's pull request",
+						Aliases: []string{"m"},
+						Action:  handlePRMerge(stdout, stderr, ghClient),
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "strategy",
+								Usage:   "merge strategy, one of squash, merge or rebase",
+								Aliases: []string{"s"},
+							},
+						},
+					},
 				},
 			},
 			{
@@ -72,22 +175,158 @@ func Run(
 				Usage:   "For working with engineering diaries",
 				Aliases: []string{"d"},
 				Subcommands: []*cli.Command{
+					{
+						Name:      "init",
+						Usage:     "Create a new diary repository, defaults to the configured diary.repo",
+						ArgsUsage: "[path]",
+						Action:    handleDiaryInit(stdout, stderr, diaryRepo),
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "remote",
+								Usage: "URL of the remote to sync entries to",
+							},
+						},
+					},
 					{
 						Name:    "new",
 						Usage:   "Create a new diary entry",
 						Aliases: []string{"n"},
-						Action:  handleDiaryNew(stdout, stderr),
+						Action:  handleDiaryNew(stdout, stderr, diaryRepo),
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "date",
+								Usage: "create the entry for another day, as YYYY-MM-DD",
+							},
+						},
 					},
 					{
 						Name:    "open",
 						Usage:   "Open today's diary entry",
 						Aliases: []string{"o"},
-						Action:  handleDiaryOpen(stdout, stderr),
+						Action:  handleDiaryOpen(stdout, stderr, diaryRepo),
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "sync-on-close",
+								Usage: "wait for the editor to exit then sync, GUI editors need their wait flag in $EDITOR, e.g. code --wait",
+							},
+						},
+					},
+					{
+						Name:      "search",
+						Usage:     "Search diary entries, #tag words filter on tags",
+						UsageText: "dev diary search [--regex] [--since DATE] [--until DATE] <query|#tag>...",
+						ArgsUsage: "<query|#tag>...",
+						Action:    handleDiarySearch(stdout, stderr, diaryRepo),
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:    "regex",
+								Aliases: []string{"r"},
+								Usage:   "treat the query as a regular expression rather than a phrase",
+							},
+							&cli.StringFlag{
+								Name:  "since",
+								Usage: "only search entries on or after this date, as YYYY-MM-DD",
+							},
+							&cli.StringFlag{
+								Name:  "until",
+								Usage: "only search entries on or before this date, as YYYY-MM-DD",
+							},
+							&cli.IntFlag{
+								Name:    "context",
+								Aliases: []string{"C"},
+								Value:   2,
+								Usage:   "lines of context to show around each match",
+							},
+							&cli.BoolFlag{
+								Name:  "no-open",
+								Usage: "print matches without offering to open one",
+							},
+						},
+					},
+					{
+						Name:   "summary",
+						Usage:  "Roll up this week's entries into a Markdown report",
+						Action: handleDiarySummary(stdout, stderr, diaryRepo),
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "week",
+								Usage: "summarise this week, Monday to Sunday (default)",
+							},
+							&cli.BoolFlag{
+								Name:  "month",
+								Usage: "summarise this month",
+							},
+							&cli.StringFlag{
+								Name:  "range",
+								Usage: "summarise the days between two dates, inclusively, as YYYY-MM-DD..YYYY-MM-DD",
+							},
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "write the report to a file rather than stdout",
+							},
+						},
 					},
 					{
 						Name:   "sync",
-						Usage:  "Sync diary entries to remote",
-						Action: handleDiarySync(stdout, stderr),
+						Usage:  "Commit diary entries, rebase onto the remote and push",
+						Action: handleDiarySync(stdout, stderr, diaryRepo),
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "dry-run",
+								Usage: "print the commit message without committing or pushing",
+							},
+							&cli.BoolFlag{
+								Name:  "watch",
+								Usage: "keep running, syncing whenever entries change",
+							},
+							&cli.DurationFlag{
+								Name:  "debounce",
+								Value: 30 * time.Second,
+								Usage: "with --watch, how long entries must be unchanged before syncing",
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  "config",
+				Usage: "Read and write settings, the repo's .dev.toml overrides ~/.config/dev/config.toml",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "get",
+						Usage:     "Print the value of a setting",
+						ArgsUsage: "<key>",
+						Action:    handleConfigGet(stdout, stderr, cfg),
+					},
+					{
+						Name:      "set",
+						Usage:     "Write a setting to the config file",
+						ArgsUsage: "<key> <value>",
+						Action:    handleConfigSet(stdout, stderr),
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "local",
+								Usage: "write to the repo's .dev.toml instead",
+							},
+						},
+					},
+					{
+						Name:    "list",
+						Usage:   "Print every setting with its current value",
+						Aliases: []string{"ls"},
+						Action:  handleConfigList(stdout, stderr, cfg),
+					},
+					{
+						Name:   "path",
+						Usage:  "Print the location of the config file",
+						Action: handleConfigPath(stdout, stderr),
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "local",
+								Usage: "print the repo's .dev.toml location instead",
+							},
+						},
 					},
 				},
 			},
@@ -108,8 +347,14 @@ func Run(
 						Aliases: []string{"f"},
 						Value:   false,
 					},
+					&cli.BoolFlag{
+						Name:    "verbose",
+						Usage:   "print go test's output as it runs instead of the progress",
+						Aliases: []string{"v"},
+						Value:   false,
+					},
 				},
-				Action: handleTest(stdout, stderr),
+				Action: handleTest(stdout, stderr, config.ExpandPath(cfg.Test.FailedTestsFile)),
 			},
 		},
 	}
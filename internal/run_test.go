@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thomasgormley/dev-cli-go/internal/config"
+	"github.com/thomasgormley/dev-cli-go/internal/gh"
+	"github.com/thomasgormley/dev-cli-go/internal/gh/ghtest"
+	"github.com/urfave/cli/v2"
+)
+
+func prStatus(state gh.MergeStateStatus, checks ...gh.StatusCheckRollup) gh.PRStatusResponse {
+	var status gh.PRStatusResponse
+	status.CurrentBranch.Number = 42
+	status.CurrentBranch.Title = "ABC-123: some description"
+	status.CurrentBranch.HeadRefName = "ABC-123-some-description"
+	status.CurrentBranch.BaseRefName = "main"
+	status.CurrentBranch.MergeStateStatus = state
+	status.CurrentBranch.StatusCheckRollup = checks
+	status.CurrentBranch.Commits = []gh.PRCommit{{OID: "abc"}}
+	return status
+}
+
+var (
+	passingCheck = gh.StatusCheckRollup{Name: "build", Status: "COMPLETED", Conclusion: "SUCCESS"}
+	failingCheck = gh.StatusCheckRollup{Name: "build", Status: "COMPLETED", Conclusion: "FAILURE"}
+	runningCheck = gh.StatusCheckRollup{Name: "build", Status: "IN_PROGRESS"}
+)
+
+func TestRunPRCommands(t *testing.T) {
+	t.Setenv("TEAM_BRANCH", "")
+
+	tests := []struct {
+		name           string
+		args           []string
+		client         *ghtest.Client
+		expectedCalls  []ghtest.Call
+		expectedCode   int
+		expectedOutput []string
+	}{
+		{
+			name:   "create with title and body",
+			args:   []string{"pr", "create", "-t", "ABC-123: title", "-b", "body", "-B", "develop", "--push=false"},
+			client: &ghtest.Client{},
+			expectedCalls: []ghtest.Call{
+				{Method: "AuthStatus"},
+				{Method: "CreatePR", Args: []any{"ABC-123: title", "body", "develop", true}},
+			},
+		},
+		{
+			name:   "create ready for review",
+			args:   []string{"pr", "create", "-t", "title", "-b", "body", "--draft=false", "--push=false"},
+			client: &ghtest.Client{},
+			expectedCalls: []ghtest.Call{
+				{Method: "AuthStatus"},
+				{Method: "CreatePR", Args: []any{"title", "body", "", false}},
+			},
+		},
+		{
+			name:          "create when not authenticated",
+			args:          []string{"pr", "create", "-t", "title", "-b", "body", "--push=false"},
+			client:        &ghtest.Client{AuthStatusErr: errors.New("exit status 1")},
+			expectedCalls: []ghtest.Call{{Method: "AuthStatus"}},
+			expectedCode:  1,
+		},
+		{
+			name:          "create fails",
+			args:          []string{"pr", "create", "-t", "title", "-b", "body", "--push=false"},
+			client:        &ghtest.Client{CreatePRErr: errors.New("already exists")},
+			expectedCalls: []ghtest.Call{{Method: "AuthStatus"}, {Method: "CreatePR", Args: []any{"title", "body", "", true}}},
+			expectedCode:  1,
+		},
+		{
+			name:          "view current branch",
+			args:          []string{"pr", "view"},
+			client:        &ghtest.Client{},
+			expectedCalls: []ghtest.Call{{Method: "ViewPR", Args: []any{""}}},
+		},
+		{
+			name:          "view by number",
+			args:          []string{"pr", "v", "42"},
+			client:        &ghtest.Client{},
+			expectedCalls: []ghtest.Call{{Method: "ViewPR", Args: []any{"42"}}},
+		},
+		{
+			name:           "status",
+			args:           []string{"pr", "status"},
+			client:         &ghtest.Client{PRStatusResponses: []gh.PRStatusResponse{prStatus(gh.CLEAN, passingCheck)}},
+			expectedCalls:  []ghtest.Call{{Method: "PRStatus", Args: []any{""}}},
+			expectedOutput: []string{"#42 ABC-123: some description", "CLEAN", "1 passing"},
+		},
+		{
+			name:          "status without a pull request",
+			args:          []string{"pr", "status"},
+			client:        &ghtest.Client{},
+			expectedCalls: []ghtest.Call{{Method: "PRStatus", Args: []any{""}}},
+			expectedCode:  1,
+		},
+		{
+			name:   "merge clean pull request",
+			args:   []string{"pr", "merge", "--strategy", "squash"},
+			client: &ghtest.Client{PRStatusResponses: []gh.PRStatusResponse{prStatus(gh.CLEAN)}},
+			expectedCalls: []ghtest.Call{
+				{Method: "PRStatus", Args: []any{""}},
+				{Method: "MergePR", Args: []any{gh.MergeSquash}},
+			},
+			expectedOutput: []string{"Merging #42 with squash", "Merged ✅"},
+		},
+		{
+			name:          "merge refuses blocked pull request",
+			args:          []string{"pr", "merge", "--strategy", "squash"},
+			client:        &ghtest.Client{PRStatusResponses: []gh.PRStatusResponse{prStatus(gh.BLOCKED)}},
+			expectedCalls: []ghtest.Call{{Method: "PRStatus", Args: []any{""}}},
+			expectedCode:  1,
+		},
+		{
+			name:          "merge with invalid strategy",
+			args:          []string{"pr", "merge", "--strategy", "fast-forward"},
+			client:        &ghtest.Client{PRStatusResponses: []gh.PRStatusResponse{prStatus(gh.CLEAN)}},
+			expectedCalls: []ghtest.Call{{Method: "PRStatus", Args: []any{""}}},
+			expectedCode:  1,
+		},
+		{
+			name: "watch until checks pass then merge",
+			args: []string{"pr", "watch", "--interval", "1ms", "--merge", "--strategy", "rebase"},
+			client: &ghtest.Client{PRStatusResponses: []gh.PRStatusResponse{
+				prStatus(gh.UNSTABLE, runningCheck),
+				prStatus(gh.UNKNOWN, passingCheck),
+				prStatus(gh.CLEAN, passingCheck),
+			}},
+			expectedCalls: []ghtest.Call{
+				{Method: "PRStatus", Args: []any{""}},
+				{Method: "PRStatus", Args: []any{""}},
+				{Method: "PRStatus", Args: []any{""}},
+				{Method: "MergePR", Args: []any{gh.MergeRebase}},
+			},
+			expectedOutput: []string{"Checks passed ✅", "Merged ✅"},
+		},
+		{
+			name: "watch exits non-zero when a check fails",
+			args: []string{"pr", "watch", "--interval", "1ms", "--merge", "--strategy", "rebase"},
+			client: &ghtest.Client{PRStatusResponses: []gh.PRStatusResponse{
+				prStatus(gh.UNSTABLE, runningCheck),
+				prStatus(gh.UNSTABLE, failingCheck),
+			}},
+			expectedCalls: []ghtest.Call{
+				{Method: "PRStatus", Args: []any{""}},
+				{Method: "PRStatus", Args: []any{""}},
+			},
+			expectedCode: 1,
+		},
+		{
+			name: "watch times out",
+			args: []string{"pr", "watch", "--interval", "1ms", "--timeout", "5ms"},
+			client: &ghtest.Client{PRStatusResponses: []gh.PRStatusResponse{
+				prStatus(gh.UNSTABLE, runningCheck),
+			}},
+			expectedCode: 1,
+		},
+		{
+			name:         "watch rejects a zero interval",
+			args:         []string{"pr", "watch", "--interval", "0s"},
+			client:       &ghtest.Client{},
+			expectedCode: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, code := runCLI(t, tt.client, tt.args...)
+
+			if code != tt.expectedCode {
+				t.Errorf("expected exit code %d, got %d", tt.expectedCode, code)
+			}
+
+			if tt.expectedCalls != nil && !reflect.DeepEqual(tt.client.Calls, tt.expectedCalls) {
+				t.Errorf("expected calls %+v, got %+v", tt.expectedCalls, tt.client.Calls)
+			}
+
+			for _, expected := range tt.expectedOutput {
+				if !strings.Contains(stdout, expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, stdout)
+				}
+			}
+		})
+	}
+}
+
+// runCLI drives cli.Run with argv as if typed after `dev`, returning stdout
+// and the exit code the process would have exited with.
+func runCLI(t *testing.T, client gh.GitHubClienter, args ...string) (string, int) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	err := Run(append([]string{"dev"}, args...), &stdout, &stderr, client, config.Default(), nil, func(*cli.Context, error) {})
+
+	var exitErr cli.ExitCoder
+	switch {
+	case err == nil:
+		return stdout.String(), 0
+	case errors.As(err, &exitErr):
+		return stdout.String(), exitErr.ExitCode()
+	default:
+		t.Logf("error: %v", err)
+		return stdout.String(), 1
+	}
+}
+
+func TestRunWithInvalidConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfgErr := &config.ValidationError{File: "config.toml", Key: "github.client", Message: "must be gh or api"}
+
+	var stdout, stderr bytes.Buffer
+	if err := Run([]string{"dev", "config", "path"}, &stdout, &stderr, &ghtest.Client{}, config.Default(), cfgErr, func(*cli.Context, error) {}); err != nil {
+		t.Errorf("expected config commands to run with an invalid config, got %v", err)
+	}
+	if !strings.Contains(stderr.String(), "github.client") {
+		t.Errorf("expected a warning about the invalid config, got %q", stderr.String())
+	}
+
+	err := Run([]string{"dev", "pr", "view"}, &stdout, &stderr, &ghtest.Client{}, config.Default(), cfgErr, func(*cli.Context, error) {})
+	if !errors.Is(err, cfgErr) {
+		t.Errorf("expected other commands to fail with the config error, got %v", err)
+	}
+}
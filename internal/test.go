@@ -10,29 +10,49 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/thomasgormley/dev-cli-go/internal/git"
+	"github.com/thomasgormley/dev-cli-go/internal/gotest"
 	"github.com/urfave/cli/v2"
 )
 
 type TestInfo struct {
-	Name        string
+	Name string
+	Kind gotest.Kind
+
+	// ImportPath is the package's import path, PackagePath is the relative
+	// path passed to go test to run exactly that package, e.g. ./internal
+	ImportPath  string
 	PackagePath string
-	FileName    string
-	IsPackage   bool // true if this represents a whole package
-}
 
-var failedTestsFile = os.Getenv("HOME") + "/.dev-cli-failed-tests"
+	FileName  string
+	Line      int
+	IsPackage bool // true if this represents a whole package
+	IsSubtest bool // true if Name is Parent/subtest
+}
 
-func handleTest(stdout, stderr io.Writer) cli.ActionFunc {
-	goTest := goTest{
-		stdin:  os.Stdin,
-		stdout: stdout,
-		stderr: stderr,
-		env:    os.Environ(),
-	}
+func handleTest(stdout, stderr io.Writer, failuresFile string) cli.ActionFunc {
 	return func(ctx *cli.Context) error {
+		root, err := repoRoot()
+		if err != nil {
+			return err
+		}
+		goTest := goTest{
+			stdin:        os.Stdin,
+			stdout:       stdout,
+			stderr:       stderr,
+			env:          os.Environ(),
+			root:         root,
+			failuresFile: failuresFile,
+			verbose:      ctx.Bool("verbose"),
+		}
+
 		if ctx.Bool("all") {
 			return goTest.run(ctx.Context, "./...")
 		}
@@ -41,7 +61,7 @@ func handleTest(stdout, stderr io.Writer) cli.ActionFunc {
 			return runFailedTests(ctx, goTest, stdout)
 		}
 
-		selectedTest, err := promptForTest()
+		selectedTest, err := promptForTest(ctx.Context)
 		if err != nil {
 			return err
 		}
@@ -50,24 +70,51 @@ func handleTest(stdout, stderr io.Writer) cli.ActionFunc {
 	}
 }
 
+// repoRoot returns the root of the repository failures are remembered for,
+// the current directory when it isn't in one or git can't tell
+func repoRoot() (string, error) {
+	if root, err := git.Root(); err == nil {
+		return root, nil
+	}
+	return os.Getwd()
+}
+
+// runFailedTests re-runs the tests that failed last time in this repository,
+// package by package so each only runs its own failures.
 func runFailedTests(ctx *cli.Context, goTest goTest, stdout io.Writer) error {
-	failedTests, err := goTest.readFailedTests()
+	failures, err := gotest.LoadFailures(goTest.failuresFile)
 	if err != nil {
 		return fmt.Errorf("failed to read failed tests: %w", err)
 	}
 
-	if len(failedTests) == 0 {
+	repo := failures.Repos[goTest.root]
+	if len(repo) == 0 {
 		fmt.Fprintf(stdout, "No previously failed tests found\n")
 		return nil
 	}
 
-	fmt.Fprintf(stdout, "Running %d previously failed tests...\n", len(failedTests))
-	testPattern := buildRunPattern(failedTests...)
-	return goTest.run(ctx.Context, "./...", "-run", testPattern)
+	packages := make([]string, 0, len(repo))
+	for pkg := range repo {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+
+	fmt.Fprintf(stdout, "Running previously failed tests in %d packages...\n", len(packages))
+	var runErr error
+	for _, pkg := range packages {
+		var args []string
+		if tests := repo[pkg]; len(tests) > 0 {
+			args = []string{"-run", buildRunPattern(tests...)}
+		}
+		if err := goTest.run(ctx.Context, pkg, args...); err != nil {
+			runErr = err
+		}
+	}
+	return runErr
 }
 
-func promptForTest() (TestInfo, error) {
-	tests, err := ListTestsFromProject()
+func promptForTest(ctx context.Context) (TestInfo, error) {
+	tests, err := ListTestsFromProject(ctx)
 	if err != nil {
 		return TestInfo{}, err
 	}
@@ -105,20 +152,35 @@ func buildTestOptions(tests []TestInfo) ([]string, map[string]TestInfo) {
 		testsInPackage := packageTests[pkg]
 
 		// Add package-level option if there are multiple tests
-		if len(testsInPackage) > 1 {
-			packageOption := fmt.Sprintf("📦 %s (all %d tests)", pkg, len(testsInPackage))
+		if n := countTopLevel(testsInPackage); n > 1 {
+			packageOption := fmt.Sprintf("📦 %s (all %d tests)", pkg, n)
 			testOptions = append(testOptions, packageOption)
 			testLookup[packageOption] = TestInfo{
-				Name:        "",
-				PackagePath: "./" + pkg + "/...",
-				FileName:    "",
+				ImportPath:  testsInPackage[0].ImportPath,
+				PackagePath: pkg,
+				IsPackage:   true,
+			}
+		}
+
+		// Offer the package and everything below it separately, so running
+		// a package never runs its subpackages by surprise
+		if n := countSubtree(packageTests, pkg); n > countTopLevel(testsInPackage) {
+			subtree := subtreePattern(pkg)
+			subtreeOption := fmt.Sprintf("📦 %s (package and subpackages, %d tests)", subtree, n)
+			testOptions = append(testOptions, subtreeOption)
+			testLookup[subtreeOption] = TestInfo{
+				ImportPath:  testsInPackage[0].ImportPath + "/...",
+				PackagePath: subtree,
 				IsPackage:   true,
 			}
 		}
 
 		// Add individual test options for this package
 		for _, test := range testsInPackage {
-			uniqueName := fmt.Sprintf(" 🧪 %s", test.Name)
+			uniqueName := fmt.Sprintf(" %s %s", kindIcons[test.Kind], test.Name)
+			if test.IsSubtest {
+				uniqueName = fmt.Sprintf("    ↳ %s", test.Name)
+			}
 			testOptions = append(testOptions, uniqueName)
 			testLookup[uniqueName] = test
 		}
@@ -127,11 +189,54 @@ func buildTestOptions(tests []TestInfo) ([]string, map[string]TestInfo) {
 	return testOptions, testLookup
 }
 
+// countSubtree counts the top-level tests in pkg and the packages below it
+func countSubtree(packageTests map[string][]TestInfo, pkg string) int {
+	n := 0
+	for other, tests := range packageTests {
+		if other == pkg || isSubpackage(pkg, other) {
+			n += countTopLevel(tests)
+		}
+	}
+	return n
+}
+
+func isSubpackage(parent, pkg string) bool {
+	if parent == "." {
+		return pkg != "."
+	}
+	return strings.HasPrefix(pkg, parent+"/")
+}
+
+// subtreePattern turns a package path into the pattern for it and its
+// subpackages, e.g. ./internal -> ./internal/...
+func subtreePattern(pkg string) string {
+	if pkg == "." {
+		return "./..."
+	}
+	return pkg + "/..."
+}
+
+func countTopLevel(tests []TestInfo) int {
+	n := 0
+	for _, test := range tests {
+		if !test.IsSubtest {
+			n++
+		}
+	}
+	return n
+}
+
+var kindIcons = map[gotest.Kind]string{
+	gotest.KindTest:      "🧪",
+	gotest.KindBenchmark: "⏱️",
+	gotest.KindFuzz:      "🎲",
+	gotest.KindExample:   "📖",
+}
+
 func groupTestsByPackage(tests []TestInfo) map[string][]TestInfo {
 	packageTests := make(map[string][]TestInfo)
 	for _, test := range tests {
-		pkg := strings.TrimPrefix(strings.TrimSuffix(test.PackagePath, "/..."), "./")
-		packageTests[pkg] = append(packageTests[pkg], test)
+		packageTests[test.PackagePath] = append(packageTests[test.PackagePath], test)
 	}
 	return packageTests
 }
@@ -160,6 +265,10 @@ func runSelectedTest(ctx *cli.Context, goTest goTest, selectedTest TestInfo) err
 	}
 
 	runPattern := buildRunPattern(selectedTest.Name)
+	if selectedTest.Kind == gotest.KindBenchmark {
+		// Skip the tests, benchmarks only run with -bench
+		return goTest.run(ctx.Context, selectedTest.PackagePath, "-run", "^$", "-bench", runPattern)
+	}
 	return goTest.run(ctx.Context, selectedTest.PackagePath, "-run", runPattern)
 }
 
@@ -168,130 +277,194 @@ func contains(filterValue string, optValue string, optIndex int) bool {
 	return strings.Contains(optValue, filterValue)
 }
 
-func ListTests(reader io.Reader) ([]TestInfo, error) {
-	var tests []TestInfo
-
-	// Parse the ripgrep output line by line
-	scanner := bufio.NewScanner(reader)
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Format: filename:line_number:func TestName(
-		parts := bytes.SplitN([]byte(line), []byte(":"), 3)
-		if len(parts) < 3 {
-			continue
-		}
-
-		filename := string(parts[0])
-		content := string(parts[2])
-
-		// Extract test function name
-		// Looking for "func TestXxx(" pattern
-		if bytes.Contains([]byte(content), []byte("func Test")) {
-			start := bytes.Index([]byte(content), []byte("func "))
-			if start == -1 {
-				continue
-			}
-
-			// Find the function name
-			funcStart := start + 5 // len("func ")
-			funcEnd := bytes.Index([]byte(content[funcStart:]), []byte("("))
-			if funcEnd == -1 {
-				continue
-			}
-
-			testName := string(content[funcStart : funcStart+funcEnd])
-			// Only add if it starts with "Test" and is not "TestMain"
-			if bytes.HasPrefix([]byte(testName), []byte("Test")) && testName != "TestMain" {
-				// Extract package path from filename
-				packagePath := extractPackagePath(filename)
-
-				tests = append(tests, TestInfo{
-					Name:        testName,
-					PackagePath: packagePath,
-					FileName:    filename,
-				})
-			}
-		}
+// ListTestsFromProject finds the tests, benchmarks, fuzz tests and examples
+// in the packages below the current directory.
+func ListTestsFromProject(ctx context.Context) ([]TestInfo, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error scanning ripgrep output: %w", err)
+	funcs, err := gotest.Discover(ctx, wd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tests: %w", err)
 	}
 
+	tests := make([]TestInfo, 0, len(funcs))
+	for _, fn := range funcs {
+		test := testInfoFor(wd, fn)
+		tests = append(tests, test)
+
+		// Subtests follow their parent so they're listed beneath it
+		for _, sub := range fn.Subtests {
+			subtest := test
+			subtest.Name = test.Name + "/" + sub
+			subtest.IsSubtest = true
+			tests = append(tests, subtest)
+		}
+	}
 	return tests, nil
 }
 
-func ListTestsFromProject() ([]TestInfo, error) {
-	// Use ripgrep to find all Go test functions in *_test.go files only
-	cmd := exec.Command("rg", "--type", "go", "-g", "*_test.go", "^func Test[A-Za-z0-9_]+\\(", "-n", "--no-heading")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to run ripgrep: %w", err)
+func testInfoFor(wd string, fn gotest.Func) TestInfo {
+	fileName := fn.File
+	if rel, err := filepath.Rel(wd, fn.File); err == nil {
+		fileName = rel
+	}
+	return TestInfo{
+		Name:        fn.Name,
+		Kind:        fn.Kind,
+		ImportPath:  fn.ImportPath,
+		PackagePath: packagePath(wd, fn.Dir),
+		FileName:    fileName,
+		Line:        fn.Line,
 	}
-
-	return ListTests(bytes.NewReader(output))
 }
 
-func extractPackagePath(filename string) string {
-	// Convert filename to package path
-	// e.g., "internal/pr_test.go" -> "./internal/..."
-	dir := filepath.Dir(filename)
-	if dir == "." || dir == "" {
-		return "./"
+// packagePath returns the relative path go test needs to run exactly the
+// package in dir, e.g. ./internal, falling back to dir itself when it isn't
+// below wd.
+func packagePath(wd, dir string) string {
+	rel, err := filepath.Rel(wd, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return dir
+	}
+	if rel == "." {
+		return "."
 	}
-	return "./" + dir + "/..."
+	return "./" + filepath.ToSlash(rel)
 }
 
 type goTest struct {
 	dir string
 	env []string
 
+	// root is the repository the failures are remembered for, so --failed
+	// only re-runs the failures of the repository it's in
+	root         string
+	failuresFile string
+
+	// verbose prints go test's output as it runs rather than the progress
+	// and the output of failing tests
+	verbose bool
+
 	stdin  io.Reader
 	stdout io.Writer
 	stderr io.Writer
 }
 
-type testEvent struct {
-	Action  string `json:"Action"`
-	Package string `json:"Package"`
-	Test    string `json:"Test"`
-	Output  string `json:"Output,omitempty"`
-}
-
 func (gt goTest) run(ctx context.Context, path string, args ...string) error {
-	cmd := gt.prepareCmd(ctx, path, args...)
+	cmd := gt.prepareCmd(ctx, path, append([]string{"-json"}, args...)...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
 
-	// Capture output while writing to stdout
-	var capturedOutput bytes.Buffer
-	multiWriter := io.MultiWriter(gt.stdout, &capturedOutput)
-	cmd.Stdout = multiWriter
+	// Hold build errors back until the end, the progress view would be
+	// drawn over them
+	var stderr bytes.Buffer
+	if !gt.verbose {
+		cmd.Stderr = &stderr
+	}
 
 	fmt.Fprintf(gt.stdout, "💨 %s\n", strings.Join(cmd.Args, " "))
-	err := cmd.Run()
-
-	// Process captured output through test2json even if tests failed
-	failures, parseErr := gt.parseTestOutput(ctx, capturedOutput.Bytes())
-	if parseErr != nil {
-		// Don't fail the whole command if parsing fails
-		fmt.Fprintf(gt.stderr, "Warning: failed to parse test output: %v\n", parseErr)
+	if err := cmd.Start(); err != nil {
+		return err
 	}
 
-	// Save failures for --failed flag
-	if len(failures) > 0 {
-		gt.saveFailedTests(failures)
+	events := make(chan gotest.Event)
+	var readErr error
+	go func() {
+		readErr = decodeEvents(stdout, events)
+		close(events)
+	}()
+
+	// Tests that didn't run keep their failures when only some of them ran
+	results := gotest.Results{Filtered: slices.Contains(args, "-run")}
+	var progress *testProgress
+	if gt.verbose {
+		for event := range events {
+			fmt.Fprint(gt.stdout, event.Output)
+			results.Add(event)
+		}
 	} else {
-		// All tests passed, remove any existing failed tests file
-		os.Remove(failedTestsFile)
+		progress = newTestProgress(time.Now())
+		gt.showProgress(events, progress, &results)
+	}
+	if readErr != nil {
+		fmt.Fprintf(gt.stderr, "Warning: failed to read test output: %v\n", readErr)
 	}
 
-	// Return the original error (test failures are expected)
+	// Return the original error (test failures are expected). Wait for go
+	// test to exit first, so all of stderr has been copied
+	err = cmd.Wait()
+	if progress != nil {
+		gt.stderr.Write(stderr.Bytes())
+		progress.writeFailures(gt.stdout)
+		progress.writeSummary(gt.stdout, time.Now())
+	}
+	gt.saveFailures(&results)
 	return err
 }
 
+// decodeEvents sends the events go test streams from r. Lines that aren't
+// events are sent as output that doesn't belong to a package.
+func decodeEvents(r io.Reader, events chan<- gotest.Event) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var event gotest.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			event = gotest.Event{Action: "output", Output: scanner.Text() + "\n"}
+		}
+		events <- event
+	}
+	return scanner.Err()
+}
+
+// showProgress redraws the progress of the run as events arrive, until they
+// stop, when the last frame is cleared again. Nothing is drawn when stdout
+// isn't a terminal.
+func (gt goTest) showProgress(events <-chan gotest.Event, progress *testProgress, results *gotest.Results) {
+	draw := isTerminal(gt.stdout)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	var drawn int
+	clearFrame := func() {
+		// Move back up over the previous frame and clear it
+		if drawn > 0 {
+			fmt.Fprintf(gt.stdout, "\033[%dA\033[J", drawn)
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				clearFrame()
+				return
+			}
+			results.Add(event)
+			progress.Add(event)
+
+		case now := <-ticker.C:
+			if !draw {
+				continue
+			}
+			var frame bytes.Buffer
+			progress.renderFrame(&frame, now)
+			clearFrame()
+			gt.stdout.Write(frame.Bytes())
+			drawn = bytes.Count(frame.Bytes(), []byte{'\n'})
+		}
+	}
+}
+
 func (gt goTest) prepareCmd(ctx context.Context, path string, args ...string) *exec.Cmd {
 	cmdArgs := append([]string{"test", path, "-count=1"}, args...)
 	cmd := exec.CommandContext(ctx, "go", cmdArgs...)
+	cmd.Dir = gt.dir
 	cmd.Stdin = gt.stdin
 	cmd.Stderr = gt.stderr
 	cmd.Env = gt.env
@@ -299,90 +472,74 @@ func (gt goTest) prepareCmd(ctx context.Context, path string, args ...string) *e
 	return cmd
 }
 
-func (gt goTest) parseTestOutput(ctx context.Context, output []byte) ([]string, error) {
-	// Run go tool test2json on the captured output
-	cmd := exec.CommandContext(ctx, "go", "tool", "test2json")
-	cmd.Stdin = bytes.NewReader(output)
-	cmd.Env = gt.env
-
-	jsonOutput, err := cmd.Output()
+// saveFailures remembers the failures of the packages that ran for --failed,
+// forgetting those that now pass
+func (gt goTest) saveFailures(results *gotest.Results) {
+	failures, err := gotest.LoadFailures(gt.failuresFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to run test2json: %w", err)
+		fmt.Fprintf(gt.stderr, "Warning: failed to read %s: %v\n", gt.failuresFile, err)
+		return
 	}
 
-	// Parse the JSON output to extract failures
-	var failures []string
-	scanner := bufio.NewScanner(bytes.NewReader(jsonOutput))
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		var event testEvent
-		if json.Unmarshal([]byte(line), &event) == nil {
-			if event.Action == "fail" && event.Test != "" {
-				failures = append(failures, event.Test)
-			}
-		}
+	failures.Record(gt.root, results)
+	if err := failures.Save(gt.failuresFile); err != nil {
+		fmt.Fprintf(gt.stderr, "Warning: failed to save failed tests to %s: %v\n", gt.failuresFile, err)
 	}
-
-	return failures, scanner.Err()
 }
 
-func (gt goTest) saveFailedTests(failures []string) {
-	f, err := os.Create(failedTestsFile)
-	if err != nil {
-		fmt.Fprintf(gt.stderr, "Warning: failed to create %s: %v\n", failedTestsFile, err)
-		return
+// buildRunPattern returns a -run pattern matching exactly the tests, so
+// TestFoo doesn't also run TestFooBar. Subtests, named Parent/subtest, are
+// matched exactly at each level, with their names escaped the way go test
+// reports them.
+func buildRunPattern(testNames ...string) string {
+	if len(testNames) == 0 {
+		return ""
 	}
-	defer f.Close()
 
-	writer := bufio.NewWriter(f)
-	for _, failure := range failures {
-		if _, err := fmt.Fprintln(writer, failure); err != nil {
-			fmt.Fprintf(gt.stderr, "Warning: failed to write test failure: %v\n", err)
-			return
+	// -run splits alternatives across levels into every combination, so
+	// subtests of several tests are run as their parents
+	var parents []string
+	seen := make(map[string]bool)
+	for _, test := range testNames {
+		parent, _, _ := strings.Cut(test, "/")
+		if !seen[parent] {
+			seen[parent] = true
+			parents = append(parents, regexp.QuoteMeta(parent))
 		}
 	}
-
-	if err := writer.Flush(); err != nil {
-		fmt.Fprintf(gt.stderr, "Warning: failed to flush failed tests to file: %v\n", err)
+	if len(parents) > 1 {
+		return anchoredPattern(parents)
 	}
-}
 
-func (gt goTest) readFailedTests() ([]string, error) {
-	file, err := os.Open(failedTestsFile)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return []string{}, nil // No failed tests file exists
+	var levels [][]string
+	for _, test := range testNames {
+		names := strings.Split(test, "/")
+		if len(names) == 1 {
+			// The parent itself runs all its subtests
+			return anchoredPattern(parents)
 		}
-		return nil, err
-	}
-	defer file.Close()
-
-	var tests []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		test := scanner.Text()
-		if test != "" {
-			tests = append(tests, test)
+		for i, name := range names {
+			if i == len(levels) {
+				levels = append(levels, nil)
+			}
+			name = regexp.QuoteMeta(gotest.SubtestName(name))
+			if !slices.Contains(levels[i], name) {
+				levels[i] = append(levels[i], name)
+			}
 		}
 	}
 
-	return tests, scanner.Err()
-}
-
-func buildRunPattern(testNames ...string) string {
-	if len(testNames) == 0 {
-		return ""
-	}
-	if len(testNames) == 1 {
-		// For single test, just use the name without anchors to allow partial matching
-		return testNames[0]
+	patterns := make([]string, len(levels))
+	for i, names := range levels {
+		patterns[i] = anchoredPattern(names)
 	}
+	return strings.Join(patterns, "/")
+}
 
-	pattern := "^(" + testNames[0]
-	for _, test := range testNames[1:] {
-		pattern += "|" + test
+// anchoredPattern matches exactly one of names, which are already escaped
+func anchoredPattern(names []string) string {
+	if len(names) == 1 {
+		return "^" + names[0] + "$"
 	}
-	pattern += ")$"
-	return pattern
+	return "^(" + strings.Join(names, "|") + ")$"
 }
@@ -1,154 +1,146 @@
 package cli
 
 import (
-	"strings"
+	"reflect"
 	"testing"
+
+	"github.com/thomasgormley/dev-cli-go/internal/gotest"
 )
 
-func TestListTests(t *testing.T) {
+func TestTestInfoFor(t *testing.T) {
 	tests := []struct {
 		name     string
-		input    string
-		expected []TestInfo
+		fn       gotest.Func
+		expected TestInfo
 	}{
 		{
-			name:  "single test function",
-			input: `internal/pr_test.go:5:func TestPrTitleFromBranch(t *testing.T) {`,
-			expected: []TestInfo{
-				{Name: "TestPrTitleFromBranch", PackagePath: "./internal/...", FileName: "internal/pr_test.go"},
-			},
-		},
-		{
-			name: "multiple test functions in one file",
-			input: `internal/example_test.go:10:func TestOne(t *testing.T) {
-internal/example_test.go:20:func TestTwo(t *testing.T) {
-internal/example_test.go:30:func TestThree(t *testing.T) {`,
-			expected: []TestInfo{
-				{Name: "TestOne", PackagePath: "./internal/...", FileName: "internal/example_test.go"},
-				{Name: "TestTwo", PackagePath: "./internal/...", FileName: "internal/example_test.go"},
-				{Name: "TestThree", PackagePath: "./internal/...", FileName: "internal/example_test.go"},
+			name: "test in a subpackage",
+			fn: gotest.Func{
+				Name:       "TestPrTitleFromBranch",
+				Kind:       gotest.KindTest,
+				ImportPath: "example.com/repo/internal",
+				Dir:        "/repo/internal",
+				File:       "/repo/internal/pr_test.go",
+				Line:       12,
 			},
-		},
-		{
-			name: "multiple files with tests",
-			input: `internal/pr_test.go:5:func TestPrTitleFromBranch(t *testing.T) {
-internal/another_test.go:5:func TestExampleOne(t *testing.T) {
-internal/another_test.go:14:func TestExampleTwo(t *testing.T) {`,
-			expected: []TestInfo{
-				{Name: "TestPrTitleFromBranch", PackagePath: "./internal/...", FileName: "internal/pr_test.go"},
-				{Name: "TestExampleOne", PackagePath: "./internal/...", FileName: "internal/another_test.go"},
-				{Name: "TestExampleTwo", PackagePath: "./internal/...", FileName: "internal/another_test.go"},
+			expected: TestInfo{
+				Name:        "TestPrTitleFromBranch",
+				Kind:        gotest.KindTest,
+				ImportPath:  "example.com/repo/internal",
+				PackagePath: "./internal",
+				FileName:    "internal/pr_test.go",
+				Line:        12,
 			},
 		},
 		{
-			name:  "test with underscores and numbers",
-			input: `internal/complex_test.go:1:func TestWithUnderscores_AndNumbers123(t *testing.T) {`,
-			expected: []TestInfo{
-				{Name: "TestWithUnderscores_AndNumbers123", PackagePath: "./internal/...", FileName: "internal/complex_test.go"},
+			name: "benchmark in the root package",
+			fn: gotest.Func{
+				Name:       "BenchmarkRun",
+				Kind:       gotest.KindBenchmark,
+				ImportPath: "example.com/repo",
+				Dir:        "/repo",
+				File:       "/repo/main_test.go",
+				Line:       3,
 			},
-		},
-		{
-			name:     "empty input",
-			input:    "",
-			expected: []TestInfo{},
-		},
-		{
-			name: "non-test functions should be ignored",
-			input: `internal/helper.go:10:func someHelper() {
-internal/test.go:20:func TestValidFunction(t *testing.T) {
-internal/main.go:5:func main() {`,
-			expected: []TestInfo{
-				{Name: "TestValidFunction", PackagePath: "./internal/...", FileName: "internal/test.go"},
-			},
-		},
-		{
-			name: "TestMain should be filtered out",
-			input: `internal/example_test.go:5:func TestMain(m *testing.M) {
-internal/example_test.go:10:func TestValid(t *testing.T) {`,
-			expected: []TestInfo{
-				{Name: "TestValid", PackagePath: "./internal/...", FileName: "internal/example_test.go"},
+			expected: TestInfo{
+				Name:        "BenchmarkRun",
+				Kind:        gotest.KindBenchmark,
+				ImportPath:  "example.com/repo",
+				PackagePath: ".",
+				FileName:    "main_test.go",
+				Line:        3,
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			reader := strings.NewReader(tt.input)
-			result, err := ListTests(reader)
-
-			if err != nil {
-				t.Fatalf("ListTests() returned error: %v", err)
+			if got := testInfoFor("/repo", tt.fn); got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
 			}
+		})
+	}
+}
 
-			// Check if slices are equal
-			if len(result) != len(tt.expected) {
-				t.Fatalf("Expected %d tests, got %d", len(tt.expected), len(result))
-			}
+func TestBuildTestOptions(t *testing.T) {
+	tests := []TestInfo{
+		{Name: "TestTwo", ImportPath: "example.com/repo/internal", PackagePath: "./internal"},
+		{Name: "TestOne", ImportPath: "example.com/repo/internal", PackagePath: "./internal"},
+		{Name: "BenchmarkOne", Kind: gotest.KindBenchmark, ImportPath: "example.com/repo/internal", PackagePath: "./internal"},
+		{Name: "TestGit", ImportPath: "example.com/repo/internal/git", PackagePath: "./internal/git"},
+		{Name: "TestRoot", ImportPath: "example.com/repo", PackagePath: "."},
+	}
 
-			for i, expected := range tt.expected {
-				if i >= len(result) {
-					t.Errorf("Missing test at index %d: %+v", i, expected)
-					continue
-				}
+	options, lookup := buildTestOptions(tests)
 
-				actual := result[i]
-				if actual.Name != expected.Name {
-					t.Errorf("Test %d: expected name %s, got %s", i, expected.Name, actual.Name)
-				}
-				if actual.PackagePath != expected.PackagePath {
-					t.Errorf("Test %d: expected package path %s, got %s", i, expected.PackagePath, actual.PackagePath)
-				}
-				if actual.FileName != expected.FileName {
-					t.Errorf("Test %d: expected filename %s, got %s", i, expected.FileName, actual.FileName)
-				}
-			}
-		})
+	expected := []string{
+		"📦 ./... (package and subpackages, 5 tests)",
+		" 🧪 TestRoot",
+		"📦 ./internal (all 3 tests)",
+		"📦 ./internal/... (package and subpackages, 4 tests)",
+		" 🧪 TestTwo",
+		" 🧪 TestOne",
+		" ⏱️ BenchmarkOne",
+		" 🧪 TestGit",
+	}
+	if !reflect.DeepEqual(options, expected) {
+		t.Errorf("expected options:\n%q\ngot:\n%q", expected, options)
+	}
+
+	if pkg := lookup["📦 ./internal (all 3 tests)"]; !pkg.IsPackage || pkg.PackagePath != "./internal" {
+		t.Errorf("expected the package option to run exactly ./internal, got %+v", pkg)
+	}
+	if pkg := lookup["📦 ./internal/... (package and subpackages, 4 tests)"]; !pkg.IsPackage || pkg.PackagePath != "./internal/..." {
+		t.Errorf("expected the subpackages option to run ./internal/..., got %+v", pkg)
+	}
+	if bench := lookup[" ⏱️ BenchmarkOne"]; bench.Kind != gotest.KindBenchmark {
+		t.Errorf("expected the benchmark option to keep its kind, got %+v", bench)
+	}
+}
+
+func TestBuildTestOptionsNestsSubtests(t *testing.T) {
+	tests := []TestInfo{
+		{Name: "TestAdd", PackagePath: "./calc"},
+		{Name: "TestAdd/adds numbers", PackagePath: "./calc", IsSubtest: true},
+		{Name: "TestSub", PackagePath: "./calc"},
+	}
+
+	options, lookup := buildTestOptions(tests)
+
+	expected := []string{
+		"📦 ./calc (all 2 tests)",
+		" 🧪 TestAdd",
+		"    ↳ TestAdd/adds numbers",
+		" 🧪 TestSub",
+	}
+	if !reflect.DeepEqual(options, expected) {
+		t.Errorf("expected options %q, got %q", expected, options)
+	}
+	if sub := lookup["    ↳ TestAdd/adds numbers"]; sub.Name != "TestAdd/adds numbers" {
+		t.Errorf("expected the subtest option to select the subtest, got %+v", sub)
 	}
 }
 
-func TestListTestsMalformedInput(t *testing.T) {
+func TestBuildRunPattern(t *testing.T) {
 	tests := []struct {
-		name  string
-		input string
+		name     string
+		tests    []string
+		expected string
 	}{
-		{
-			name:  "line with too few colons",
-			input: "invalid-line-format",
-		},
-		{
-			name:  "line with no function declaration",
-			input: "file.go:10:some random text",
-		},
-		{
-			name: "mixed valid and invalid lines",
-			input: `invalid-line
-internal/test.go:5:func TestValid(t *testing.T) {
-another-invalid-line`,
-		},
+		{name: "none", expected: ""},
+		{name: "single test is anchored", tests: []string{"TestAdd"}, expected: "^TestAdd$"},
+		{name: "subtest", tests: []string{"TestAdd/adds numbers"}, expected: "^TestAdd$/^adds_numbers$"},
+		{name: "nested subtest with regexp characters", tests: []string{"TestAdd/a+b/(nested)"}, expected: `^TestAdd$/^a\+b$/^\(nested\)$`},
+		{name: "several tests", tests: []string{"TestAdd", "TestSub"}, expected: "^(TestAdd|TestSub)$"},
+		{name: "several subtests run their parents", tests: []string{"TestAdd", "TestAdd/one", "TestSub/two"}, expected: "^(TestAdd|TestSub)$"},
+		{name: "subtests of one parent", tests: []string{"TestAdd/one", "TestAdd/two"}, expected: "^TestAdd$/^(one|two)$"},
+		{name: "a parent runs all its subtests", tests: []string{"TestAdd/one", "TestAdd"}, expected: "^TestAdd$"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			reader := strings.NewReader(tt.input)
-			result, err := ListTests(reader)
-
-			if err != nil {
-				t.Fatalf("ListTests() should not return error for malformed input: %v", err)
-			}
-
-			// For the mixed case, we should still get the valid test
-			if tt.name == "mixed valid and invalid lines" {
-				expected := []TestInfo{
-					{Name: "TestValid", PackagePath: "./internal/...", FileName: "internal/test.go"},
-				}
-
-				if len(result) != len(expected) {
-					t.Errorf("Expected %d tests, got %d", len(expected), len(result))
-				} else if len(result) > 0 {
-					if result[0].Name != expected[0].Name {
-						t.Errorf("Expected test name %s, got %s", expected[0].Name, result[0].Name)
-					}
-				}
+			if got := buildRunPattern(tt.tests...); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
 			}
 		})
 	}
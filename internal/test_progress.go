@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thomasgormley/dev-cli-go/internal/gotest"
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+type packageState int
+
+const (
+	packageRunning packageState = iota
+	packagePassed
+	packageFailed
+	packageSkipped
+)
+
+// testOutput is a line of output and the test that printed it, empty for the
+// package itself
+type testOutput struct {
+	test string
+	line string
+}
+
+// testProgress follows a `go test -json` run, keeping the output of tests
+// until they pass so only the output of failing tests is printed at the end.
+type testProgress struct {
+	start    time.Time
+	packages map[string]packageState
+	order    []string
+
+	// output holds each package's output, in the order it was printed, less
+	// that of the tests that have passed
+	output map[string][]testOutput
+
+	// builds holds the compiler output by the import path that was built,
+	// failedBuilds the failed build that stopped each package running
+	builds       map[string][]string
+	failedBuilds map[string]string
+
+	// unattributed holds output that doesn't belong to a package, such as
+	// lines go test printed that weren't events
+	unattributed []string
+}
+
+func newTestProgress(start time.Time) *testProgress {
+	return &testProgress{
+		start:    start,
+		packages: make(map[string]packageState),
+		output:   make(map[string][]testOutput),
+
+		builds:       make(map[string][]string),
+		failedBuilds: make(map[string]string),
+	}
+}
+
+func (p *testProgress) Add(event gotest.Event) {
+	if event.Action == "build-output" {
+		p.builds[event.ImportPath] = append(p.builds[event.ImportPath], event.Output)
+		return
+	}
+	if event.Package == "" {
+		if event.Action == "output" {
+			p.unattributed = append(p.unattributed, event.Output)
+		}
+		return
+	}
+	if _, ok := p.packages[event.Package]; !ok {
+		p.packages[event.Package] = packageRunning
+		p.order = append(p.order, event.Package)
+	}
+
+	switch event.Action {
+	case "output":
+		p.output[event.Package] = append(p.output[event.Package], testOutput{event.Test, event.Output})
+	case "pass", "skip":
+		if event.Test == "" {
+			p.packages[event.Package] = packagePassed
+			if event.Action == "skip" {
+				p.packages[event.Package] = packageSkipped
+			}
+			delete(p.output, event.Package)
+			return
+		}
+		p.discard(event.Package, event.Test)
+	case "fail":
+		if event.Test == "" {
+			p.packages[event.Package] = packageFailed
+			if event.FailedBuild != "" {
+				p.failedBuilds[event.Package] = event.FailedBuild
+			}
+		}
+	}
+}
+
+// discard drops the output of test, and of its subtests, which have passed
+// or been skipped too
+func (p *testProgress) discard(pkg, test string) {
+	var kept []testOutput
+	for _, out := range p.output[pkg] {
+		if out.test != test && !strings.HasPrefix(out.test, test+"/") {
+			kept = append(kept, out)
+		}
+	}
+	p.output[pkg] = kept
+}
+
+func (p *testProgress) count(state packageState) int {
+	n := 0
+	for _, s := range p.packages {
+		if s == state {
+			n++
+		}
+	}
+	return n
+}
+
+// renderFrame draws the spinner, package counts and elapsed time, followed by
+// the packages still running.
+func (p *testProgress) renderFrame(w io.Writer, now time.Time) {
+	elapsed := now.Sub(p.start)
+	spinner := spinnerFrames[int(elapsed/(100*time.Millisecond))%len(spinnerFrames)]
+	fmt.Fprintf(w, "%s %d running, %d passed, %d failed %s\n",
+		spinner, p.count(packageRunning), p.count(packagePassed), p.count(packageFailed), elapsed.Round(time.Second))
+
+	var running []string
+	for pkg, state := range p.packages {
+		if state == packageRunning {
+			running = append(running, pkg)
+		}
+	}
+	sort.Strings(running)
+	for _, pkg := range running {
+		fmt.Fprintf(w, "  %s\n", pkg)
+	}
+}
+
+// writeFailures prints the compiler errors and the output of the failing
+// tests of each failed package, as go test printed them
+func (p *testProgress) writeFailures(w io.Writer) {
+	for _, line := range p.unattributed {
+		fmt.Fprint(w, line)
+	}
+
+	// Packages can share a failed build, e.g. of a dependency, so print
+	// each build's errors once
+	printed := make(map[string]bool)
+	for _, pkg := range p.order {
+		if p.packages[pkg] != packageFailed {
+			continue
+		}
+		if build := p.failedBuilds[pkg]; build != "" && !printed[build] {
+			printed[build] = true
+			for _, line := range p.builds[build] {
+				fmt.Fprint(w, line)
+			}
+		}
+		for _, out := range p.output[pkg] {
+			fmt.Fprint(w, out.line)
+		}
+	}
+}
+
+func (p *testProgress) writeSummary(w io.Writer, now time.Time) {
+	elapsed := now.Sub(p.start).Round(10 * time.Millisecond)
+	if failed := p.count(packageFailed); failed > 0 {
+		fmt.Fprintf(w, "❌ %d of %d packages failed in %s\n", failed, len(p.packages)-p.count(packageSkipped), elapsed)
+		return
+	}
+	fmt.Fprintf(w, "✅ %d packages passed in %s\n", p.count(packagePassed), elapsed)
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thomasgormley/dev-cli-go/internal/gotest"
+)
+
+func TestTestProgress(t *testing.T) {
+	start := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	progress := newTestProgress(start)
+
+	for _, event := range []gotest.Event{
+		{Action: "start", Package: "ex.com/calc"},
+		{Action: "output", Package: "ex.com/calc", Test: "TestAdd", Output: "=== RUN   TestAdd\n"},
+		{Action: "output", Package: "ex.com/calc", Test: "TestAdd/one", Output: "=== RUN   TestAdd/one\n"},
+		{Action: "output", Package: "ex.com/calc", Test: "TestAdd/one", Output: "    calc_test.go:9: quiet\n"},
+		{Action: "pass", Package: "ex.com/calc", Test: "TestAdd/one"},
+		{Action: "output", Package: "ex.com/calc", Test: "TestAdd", Output: "--- PASS: TestAdd (0.00s)\n"},
+		{Action: "pass", Package: "ex.com/calc", Test: "TestAdd"},
+		{Action: "output", Package: "ex.com/calc", Test: "TestSub", Output: "=== RUN   TestSub\n"},
+		{Action: "output", Package: "ex.com/calc", Test: "TestSub", Output: "    calc_test.go:14: boom\n"},
+		{Action: "output", Package: "ex.com/calc", Test: "TestSub", Output: "--- FAIL: TestSub (0.00s)\n"},
+		{Action: "fail", Package: "ex.com/calc", Test: "TestSub"},
+		{Action: "start", Package: "ex.com/strings"},
+		{Action: "output", Package: "ex.com/strings", Test: "TestTrim", Output: "=== RUN   TestTrim\n"},
+		{Action: "start", Package: "ex.com/ok"},
+		{Action: "output", Package: "ex.com/ok", Output: "ok  \tex.com/ok\t0.01s\n"},
+		{Action: "pass", Package: "ex.com/ok"},
+	} {
+		progress.Add(event)
+	}
+
+	var frame bytes.Buffer
+	progress.renderFrame(&frame, start.Add(2*time.Second))
+	expected := "⠋ 2 running, 1 passed, 0 failed 2s\n  ex.com/calc\n  ex.com/strings\n"
+	if frame.String() != expected {
+		t.Errorf("expected frame %q, got %q", expected, frame.String())
+	}
+
+	progress.Add(gotest.Event{Action: "output", Package: "ex.com/calc", Output: "FAIL\tex.com/calc\t0.01s\n"})
+	progress.Add(gotest.Event{Action: "fail", Package: "ex.com/calc"})
+	progress.Add(gotest.Event{Action: "build-output", ImportPath: "ex.com/strings [ex.com/strings.test]", Output: "strings_test.go:3:1: undefined: x\n"})
+	progress.Add(gotest.Event{Action: "build-output", ImportPath: "ex.com/other", Output: "other.go:1:1: unrelated\n"})
+	progress.Add(gotest.Event{Action: "fail", Package: "ex.com/strings", FailedBuild: "ex.com/strings [ex.com/strings.test]"})
+
+	var failures bytes.Buffer
+	progress.writeFailures(&failures)
+	expected = "=== RUN   TestSub\n    calc_test.go:14: boom\n--- FAIL: TestSub (0.00s)\nFAIL\tex.com/calc\t0.01s\n" +
+		"strings_test.go:3:1: undefined: x\n" +
+		"=== RUN   TestTrim\n"
+	if failures.String() != expected {
+		t.Errorf("expected only the failing output:\n%s\ngot:\n%s", expected, failures.String())
+	}
+
+	var summary bytes.Buffer
+	progress.writeSummary(&summary, start.Add(3*time.Second))
+	if expected := "❌ 2 of 3 packages failed in 3s\n"; summary.String() != expected {
+		t.Errorf("expected summary %q, got %q", expected, summary.String())
+	}
+}
+
+func TestDecodeEvents(t *testing.T) {
+	input := `{"Action":"start","Package":"ex.com/calc"}
+not an event
+{"Action":"pass","Package":"ex.com/calc"}
+`
+	events := make(chan gotest.Event)
+	var err error
+	go func() {
+		err = decodeEvents(strings.NewReader(input), events)
+		close(events)
+	}()
+
+	var got []gotest.Event
+	for event := range events {
+		got = append(got, event)
+	}
+	if err != nil {
+		t.Fatalf("decodeEvents() returned error: %v", err)
+	}
+
+	expected := []gotest.Event{
+		{Action: "start", Package: "ex.com/calc"},
+		{Action: "output", Output: "not an event\n"},
+		{Action: "pass", Package: "ex.com/calc"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
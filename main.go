@@ -5,18 +5,47 @@ import (
 	"os"
 
 	cli "github.com/thomasgormley/dev-cli-go/internal"
+	"github.com/thomasgormley/dev-cli-go/internal/config"
 	"github.com/thomasgormley/dev-cli-go/internal/gh"
 )
 
 func main() {
+	// A bad config only fails the commands that need it, see cli.Run
+	cfg, cfgErr := config.Load()
+	if cfgErr != nil {
+		cfg = config.Default()
+	}
+
+	ghClient, err := newGitHubClient(cfg.GitHub.Client)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
 	if err := cli.Run(
 		os.Args,
 		os.Stdout,
 		os.Stderr,
-		gh.NewGitHubClient(os.Stderr, os.Stdout, os.Stdin),
+		ghClient,
+		cfg,
+		cfgErr,
 		nil,
 	); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// newGitHubClient picks between shelling out to gh (the default) and talking
+// to the GitHub API directly.
+func newGitHubClient(kind string) (gh.GitHubClienter, error) {
+	switch kind {
+	case "gh":
+		return gh.NewGitHubClient(os.Stderr, os.Stdout, os.Stdin), nil
+	case "api":
+		// The token is looked up when the first request is made
+		return gh.NewAPIClient(os.Stderr, os.Stdout, ""), nil
+	default:
+		return nil, fmt.Errorf("unknown GitHub client %q, expected gh or api", kind)
+	}
+}